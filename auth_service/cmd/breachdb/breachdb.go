@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/helyus1412/auth-service/pkg/breach"
+)
+
+// Converts a Pwned Passwords download ordered by hash into the dataset read
+// with BREACH_CHECK=file, e.g.
+//
+//	go run ./cmd/breachdb -in pwned-passwords-sha1-ordered-by-hash.txt -out pwned.bin
+//
+// or serves a converted dataset as a range API stub for BREACH_CHECK=api:
+//
+//	go run ./cmd/breachdb -serve :8090 -file pwned.bin
+func main() {
+	in := flag.String("in", "", "path of the SHA1:COUNT text file to convert")
+	out := flag.String("out", "", "path of the dataset to write")
+	serve := flag.String("serve", "", "address to serve the range API on")
+	file := flag.String("file", "", "path of the dataset to serve")
+	flag.Parse()
+
+	switch {
+	case *serve != "" && *file != "":
+		dataset, err := breach.OpenFile(*file)
+		if err != nil {
+			log.Fatalf("failed to open %s: %v", *file, err)
+		}
+		defer dataset.Close()
+
+		log.Printf("serving %s on %s", *file, *serve)
+		log.Fatal(http.ListenAndServe(*serve, breach.NewRangeHandler(dataset)))
+	case *in != "" && *out != "":
+		src, err := os.Open(*in)
+		if err != nil {
+			log.Fatalf("failed to open %s: %v", *in, err)
+		}
+		defer src.Close()
+
+		dst, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("failed to create %s: %v", *out, err)
+		}
+
+		written, err := breach.Convert(dst, src)
+		if err == nil {
+			err = dst.Close()
+		}
+		if err != nil {
+			log.Fatalf("conversion failed after %d hashes: %v", written, err)
+		}
+
+		log.Printf("wrote %d hashes to %s", written, *out)
+	default:
+		log.Fatal("either -in and -out or -serve and -file are required")
+	}
+}
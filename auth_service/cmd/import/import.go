@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/helyus1412/auth-service/config"
+	"github.com/helyus1412/auth-service/domain/auth"
+	"github.com/helyus1412/auth-service/domain/rbac"
+	"github.com/helyus1412/auth-service/domain/userimport"
+	"github.com/helyus1412/auth-service/dto"
+	"github.com/helyus1412/auth-service/pkg/databases"
+	"github.com/helyus1412/auth-service/pkg/hasher"
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+// Imports users from a JSON array in the shape of POST /users/import, e.g.
+//
+//	go run ./cmd/import -file users.json
+//
+// Users whose hash format is not recognised or whose email already exists are
+// reported and skipped.
+func main() {
+	file := flag.String("file", "", "path of the JSON file with the users to import")
+	flag.Parse()
+
+	if *file == "" {
+		log.Fatal("-file is required")
+	}
+
+	raw, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatalf("failed to read %s: %v", *file, err)
+	}
+
+	var users []dto.ImportUser
+	if err := json.Unmarshal(raw, &users); err != nil {
+		log.Fatalf("failed to parse %s: %v", *file, err)
+	}
+
+	db, err := databases.InitPostgre()
+	if err != nil {
+		log.Fatalf("failed init postgre: %v", err)
+	}
+	defer db.Close()
+
+	logger, err := logger.New(logger.Config{
+		ServiceName: config.GlobalEnv.AppName,
+		LogLevel:    zapcore.InfoLevel,
+	})
+	if err != nil {
+		log.Fatalf("failed to initialize logger: %v", err)
+	}
+	defer logger.Sync()
+
+	passwordHasher, err := hasher.NewFromConfig(hasher.Config{
+		Algorithm: config.GlobalEnv.PasswordAlgorithm,
+		Bcrypt:    hasher.BcryptParams{Cost: config.GlobalEnv.BcryptCost},
+		Argon2id: hasher.Argon2idParams{
+			Memory:      config.GlobalEnv.Argon2Memory,
+			Iterations:  config.GlobalEnv.Argon2Iterations,
+			Parallelism: config.GlobalEnv.Argon2Parallelism,
+		},
+		Firebase: hasher.FirebaseScryptParams{
+			SignerKey:     config.GlobalEnv.FirebaseSignerKey,
+			SaltSeparator: config.GlobalEnv.FirebaseSaltSep,
+			Rounds:        config.GlobalEnv.FirebaseRounds,
+			MemCost:       config.GlobalEnv.FirebaseMemCost,
+		},
+	})
+	if err != nil {
+		log.Fatalf("failed init password hasher: %v", err)
+	}
+
+	importUsecase := userimport.NewUsecase(auth.NewRepository(db, ""), rbac.NewRepository(db, ""), passwordHasher, logger)
+
+	ctx := context.Background()
+	imported := 0
+
+	for start := 0; start < len(users); start += userimport.MaxBatchSize {
+		end := min(start+userimport.MaxBatchSize, len(users))
+
+		result := importUsecase.Import(ctx, &dto.ImportUsersRequest{Users: users[start:end]})
+		if result.Error != nil {
+			log.Fatalf("import stopped after %d users: %+v", imported, result.Error)
+		}
+
+		response := result.Data.(*dto.ImportUsersResponse)
+		imported += response.Imported
+
+		for _, failure := range response.Failed {
+			log.Printf("skipped user %d (%s): %s", start+failure.Index, failure.Email, failure.Error)
+		}
+	}
+
+	log.Printf("imported %d of %d users", imported, len(users))
+}
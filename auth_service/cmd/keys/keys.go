@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/helyus1412/auth-service/config"
+	"github.com/helyus1412/auth-service/domain/keys"
+	"github.com/helyus1412/auth-service/model"
+	"github.com/helyus1412/auth-service/pkg/databases"
+	"github.com/helyus1412/auth-service/pkg/encryption"
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+// Lists signing keys, or rotates them immediately with -rotate:
+//
+//	go run ./cmd/keys -rotate
+func main() {
+	rotate := flag.Bool("rotate", false, "promote the next key and retire the active one")
+	flag.Parse()
+
+	db, err := databases.InitPostgre()
+	if err != nil {
+		log.Fatalf("failed init postgre: %v", err)
+	}
+	defer db.Close()
+
+	cipher, err := encryption.NewCipherFromBase64(config.GlobalEnv.KeyEncryptionKey)
+	if err != nil {
+		log.Fatalf("failed init key encryption: %v", err)
+	}
+
+	logger, err := logger.New(logger.Config{
+		ServiceName: config.GlobalEnv.AppName,
+		LogLevel:    zapcore.InfoLevel,
+	})
+	if err != nil {
+		log.Fatalf("failed to initialize logger: %v", err)
+	}
+	defer logger.Sync()
+
+	retirementGrace := keys.RetirementGrace(config.GlobalEnv.JWTAccessTokenTTL, config.GlobalEnv.MFATokenTTL,
+		config.GlobalEnv.EmailVerificationTTL)
+
+	keyUsecase := keys.NewUsecase(keys.NewRepository(db, ""), cipher, keys.Config{
+		Algorithm:       config.GlobalEnv.JWTSigningAlgorithm,
+		RetirementGrace: retirementGrace,
+	}, logger)
+
+	ctx := context.Background()
+
+	if err := keyUsecase.Bootstrap(ctx, nil); err != nil {
+		log.Fatalf("failed init signing keys: %v", err)
+	}
+
+	result := keyUsecase.ListKeys(ctx)
+	if *rotate {
+		result = keyUsecase.Rotate(ctx)
+	}
+	if result.Error != nil {
+		log.Fatalf("failed: %+v", result.Error)
+	}
+
+	for _, key := range result.Data.([]model.SigningKey) {
+		fmt.Printf("%-45s %-6s %-8s created=%s\n", key.KeyID, key.Algorithm, key.State, key.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+}
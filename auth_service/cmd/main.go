@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,8 +12,18 @@ import (
 
 	"github.com/helyus1412/auth-service/cmd/routes"
 	"github.com/helyus1412/auth-service/config"
+	"github.com/helyus1412/auth-service/domain/auth"
+	"github.com/helyus1412/auth-service/domain/keys"
+	"github.com/helyus1412/auth-service/domain/passkey"
+	"github.com/helyus1412/auth-service/domain/throttle"
+	"github.com/helyus1412/auth-service/pkg/breach"
 	"github.com/helyus1412/auth-service/pkg/databases"
+	"github.com/helyus1412/auth-service/pkg/denylist"
+	"github.com/helyus1412/auth-service/pkg/encryption"
+	"github.com/helyus1412/auth-service/pkg/hasher"
 	"github.com/helyus1412/auth-service/pkg/logger"
+	"github.com/helyus1412/auth-service/pkg/ratelimit"
+	"github.com/helyus1412/auth-service/pkg/token"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
@@ -62,12 +73,16 @@ func main() {
 
 	e := echo.New()
 	e.HideBanner = true
+	e.IPExtractor = echo.ExtractIPDirect()
+	if config.GlobalEnv.BehindProxy {
+		e.IPExtractor = echo.ExtractIPFromXFFHeader()
+	}
 	e.Use(otelecho.Middleware(serviceName))
 
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"}, // Allow all origins
 		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE, echo.PATCH, echo.OPTIONS},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept},
+		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
 	}))
 
 	// Initialize our custom Logger
@@ -85,7 +100,120 @@ func main() {
 		log.Fatalf("failed init postgre: %v", err)
 	}
 
-	routes.InitRoutes(e, db, tracer, logger)
+	cipher, err := encryption.NewCipherFromBase64(config.GlobalEnv.KeyEncryptionKey)
+	if err != nil {
+		log.Fatalf("failed init key encryption: %v", err)
+	}
+
+	var bootstrapKey crypto.Signer
+	if config.GlobalEnv.JWTPrivateKeyPath != "" {
+		bootstrapKey, err = token.LoadPrivateKey(config.GlobalEnv.JWTPrivateKeyPath)
+		if err != nil {
+			log.Fatalf("failed load jwt private key: %v", err)
+		}
+	}
+
+	// every token the keys sign must keep verifying until it expires, email
+	// verification links live longest
+	retirementGrace := keys.RetirementGrace(config.GlobalEnv.JWTAccessTokenTTL, config.GlobalEnv.MFATokenTTL,
+		config.GlobalEnv.EmailVerificationTTL)
+
+	keyUsecase := keys.NewUsecase(keys.NewRepository(db, ""), cipher, keys.Config{
+		Algorithm:        config.GlobalEnv.JWTSigningAlgorithm,
+		RotationInterval: config.GlobalEnv.KeyRotationInterval,
+		RetirementGrace:  retirementGrace,
+	}, logger)
+
+	if err := keyUsecase.Bootstrap(ctx, bootstrapKey); err != nil {
+		log.Fatalf("failed init signing keys: %v", err)
+	}
+
+	go keyUsecase.RunScheduler(ctx)
+
+	tokenManager, err := token.NewManager(token.Config{
+		Algorithm: config.GlobalEnv.JWTSigningAlgorithm,
+		Issuer:    config.GlobalEnv.JWTIssuer,
+		Audience:  config.GlobalEnv.JWTAudience,
+		AccessTTL: config.GlobalEnv.JWTAccessTokenTTL,
+		KeySet:    keyUsecase,
+	})
+	if err != nil {
+		log.Fatalf("failed init token manager: %v", err)
+	}
+
+	passkeyUsecase, err := passkey.NewUsecase(passkey.NewRepository(db, ""), passkey.Config{
+		RPID:          config.GlobalEnv.WebAuthnRPID,
+		RPDisplayName: config.GlobalEnv.WebAuthnRPName,
+		RPOrigins:     config.GlobalEnv.WebAuthnRPOrigins,
+		SessionTTL:    config.GlobalEnv.WebAuthnSessionTTL,
+	}, logger)
+	if err != nil {
+		log.Fatalf("failed init webauthn: %v", err)
+	}
+
+	limiter := ratelimit.NewMemoryStore()
+	if config.GlobalEnv.RateLimitBackend == "redis" {
+		rdb, err := databases.InitRedis(ctx)
+		if err != nil {
+			log.Fatalf("failed init redis: %v", err)
+		}
+		defer rdb.Close()
+
+		limiter = ratelimit.NewRedisStore(rdb, "ratelimit:")
+	}
+
+	tokenDenylist := denylist.NewPostgres(db, "")
+	if config.GlobalEnv.TokenDenylistBackend == "memory" {
+		tokenDenylist = denylist.NewMemory()
+	}
+
+	go denylist.RunCleanup(ctx, tokenDenylist, config.GlobalEnv.TokenDenylistCleanup, logger)
+	go throttle.RunCleanup(ctx, throttle.NewRepository(db, ""), config.GlobalEnv.LoginAttemptCleanup, config.GlobalEnv.LoginAttemptWindow, logger)
+
+	if config.GlobalEnv.DeletedUserGrace > 0 {
+		go auth.RunDeletedPurge(ctx, auth.NewRepository(db, ""), auth.PurgeConfig{
+			GracePeriod: config.GlobalEnv.DeletedUserGrace,
+			Anonymize:   config.GlobalEnv.DeletedUserAction == "anonymize",
+			Interval:    config.GlobalEnv.DeletedPurgeInterval,
+		}, logger)
+	}
+
+	passwordHasher, err := hasher.NewFromConfig(hasher.Config{
+		Algorithm: config.GlobalEnv.PasswordAlgorithm,
+		Bcrypt:    hasher.BcryptParams{Cost: config.GlobalEnv.BcryptCost},
+		Argon2id: hasher.Argon2idParams{
+			Memory:      config.GlobalEnv.Argon2Memory,
+			Iterations:  config.GlobalEnv.Argon2Iterations,
+			Parallelism: config.GlobalEnv.Argon2Parallelism,
+		},
+		Firebase: hasher.FirebaseScryptParams{
+			SignerKey:     config.GlobalEnv.FirebaseSignerKey,
+			SaltSeparator: config.GlobalEnv.FirebaseSaltSep,
+			Rounds:        config.GlobalEnv.FirebaseRounds,
+			MemCost:       config.GlobalEnv.FirebaseMemCost,
+		},
+	})
+	if err != nil {
+		log.Fatalf("failed init password hasher: %v", err)
+	}
+
+	var breachChecker breach.Checker
+	switch config.GlobalEnv.BreachCheck {
+	case "file":
+		dataset, err := breach.OpenFile(config.GlobalEnv.BreachFile)
+		if err != nil {
+			log.Fatalf("failed open breach dataset: %v", err)
+		}
+		defer dataset.Close()
+
+		breachChecker = dataset
+	case "api":
+		breachChecker = breach.NewRangeAPI(config.GlobalEnv.BreachAPIURL,
+			&http.Client{Timeout: config.GlobalEnv.BreachAPITimeout})
+	}
+
+	routes.InitRoutes(e, db, tracer, logger, tokenManager, keyUsecase, cipher, passkeyUsecase, limiter, tokenDenylist,
+		passwordHasher, breachChecker)
 
 	go func() {
 		port := fmt.Sprintf(":%d", config.GlobalEnv.HTTPPort)
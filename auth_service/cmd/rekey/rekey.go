@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/helyus1412/auth-service/config"
+	"github.com/helyus1412/auth-service/pkg/databases"
+	"github.com/helyus1412/auth-service/pkg/encryption"
+	"github.com/jmoiron/sqlx"
+)
+
+// encryptedColumns lists every column encrypted under KEY_ENCRYPTION_KEY with
+// the column identifying its rows.
+var encryptedColumns = []struct {
+	table string
+	id    string
+	value string
+}{
+	{"signing_keys", "id", "private_key_encrypted"},
+	{"user_totp", "user_id", "secret_encrypted"},
+}
+
+// Re-encrypts the signing keys and TOTP secrets after KEY_ENCRYPTION_KEY was
+// rotated. The previous key is read from OLD_KEY_ENCRYPTION_KEY:
+//
+//	OLD_KEY_ENCRYPTION_KEY=... KEY_ENCRYPTION_KEY=... go run ./cmd/rekey
+func main() {
+	oldKey := os.Getenv("OLD_KEY_ENCRYPTION_KEY")
+	if oldKey == "" {
+		log.Fatal("OLD_KEY_ENCRYPTION_KEY is required")
+	}
+
+	if oldKey == config.GlobalEnv.KeyEncryptionKey {
+		log.Fatal("OLD_KEY_ENCRYPTION_KEY and KEY_ENCRYPTION_KEY are the same")
+	}
+
+	oldCipher, err := encryption.NewCipherFromBase64(oldKey)
+	if err != nil {
+		log.Fatalf("failed init old key encryption: %v", err)
+	}
+
+	newCipher, err := encryption.NewCipherFromBase64(config.GlobalEnv.KeyEncryptionKey)
+	if err != nil {
+		log.Fatalf("failed init key encryption: %v", err)
+	}
+
+	db, err := databases.InitPostgre()
+	if err != nil {
+		log.Fatalf("failed init postgre: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Beginx()
+	if err != nil {
+		log.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, column := range encryptedColumns {
+		count, err := reencrypt(tx, column.table, column.id, column.value, oldCipher, newCipher)
+		if err != nil {
+			log.Fatalf("failed to re-encrypt %s.%s: %v", column.table, column.value, err)
+		}
+
+		log.Printf("re-encrypted %d rows of %s", count, column.table)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Fatalf("failed to commit: %v", err)
+	}
+}
+
+// reencrypt fails on the first value that does not decrypt with oldCipher, so
+// a wrong old key leaves every row untouched.
+func reencrypt(tx *sqlx.Tx, table, id, value string, oldCipher, newCipher *encryption.Cipher) (int, error) {
+	var rows []struct {
+		ID    int64  `db:"id"`
+		Value []byte `db:"value"`
+	}
+
+	err := tx.Select(&rows, fmt.Sprintf(`select %s as id, %s as value from public.%s for update`, id, value, table))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, row := range rows {
+		plaintext, err := oldCipher.Decrypt(row.Value)
+		if err != nil {
+			return 0, fmt.Errorf("row %d: %w", row.ID, err)
+		}
+
+		encrypted, err := newCipher.Encrypt(plaintext)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = tx.Exec(fmt.Sprintf(`update public.%s set %s = $1 where %s = $2`, table, value, id), encrypted, row.ID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(rows), nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/helyus1412/auth-service/domain/auth"
+	"github.com/helyus1412/auth-service/domain/rbac"
+	"github.com/helyus1412/auth-service/pkg/databases"
+)
+
+// Grants or revokes a role for a user, e.g. to bootstrap the first admin:
+//
+//	go run ./cmd/role -email admin@example.com -role admin
+func main() {
+	email := flag.String("email", "", "email of the user")
+	roleName := flag.String("role", "admin", "name of the role")
+	revoke := flag.Bool("revoke", false, "revoke the role instead of granting it")
+	flag.Parse()
+
+	if *email == "" {
+		log.Fatal("-email is required")
+	}
+
+	db, err := databases.InitPostgre()
+	if err != nil {
+		log.Fatalf("failed init postgre: %v", err)
+	}
+	defer db.Close()
+
+	user, err := auth.NewRepository(db, "").GetByEmail(*email)
+	if err != nil {
+		log.Fatalf("failed to get user: %v", err)
+	}
+	if user == nil {
+		log.Fatalf("user %s not found", *email)
+	}
+
+	rbacRepository := rbac.NewRepository(db, "")
+	role, err := rbacRepository.GetRoleByName(*roleName)
+	if err != nil {
+		log.Fatalf("failed to get role: %v", err)
+	}
+	if role == nil {
+		log.Fatalf("role %s not found", *roleName)
+	}
+
+	if *revoke {
+		err = rbacRepository.RevokeRole(user.ID, role.ID)
+	} else {
+		err = rbacRepository.AssignRole(user.ID, role.ID)
+	}
+	if err != nil {
+		log.Fatalf("failed to update user role: %v", err)
+	}
+
+	log.Printf("role %s updated for %s", role.Name, user.Email)
+}
@@ -3,25 +3,225 @@ package routes
 import (
 	"net/http"
 
+	"github.com/helyus1412/auth-service/config"
 	"github.com/helyus1412/auth-service/domain/auth"
+	"github.com/helyus1412/auth-service/domain/keys"
+	"github.com/helyus1412/auth-service/domain/mfa"
+	"github.com/helyus1412/auth-service/domain/oauth"
+	"github.com/helyus1412/auth-service/domain/oidc"
+	"github.com/helyus1412/auth-service/domain/passkey"
+	"github.com/helyus1412/auth-service/domain/password"
+	"github.com/helyus1412/auth-service/domain/rbac"
+	"github.com/helyus1412/auth-service/domain/refresh"
+	"github.com/helyus1412/auth-service/domain/session"
+	"github.com/helyus1412/auth-service/domain/throttle"
+	"github.com/helyus1412/auth-service/domain/userimport"
+	"github.com/helyus1412/auth-service/pkg/breach"
+	"github.com/helyus1412/auth-service/pkg/denylist"
+	"github.com/helyus1412/auth-service/pkg/encryption"
+	"github.com/helyus1412/auth-service/pkg/hasher"
 	"github.com/helyus1412/auth-service/pkg/logger"
+	"github.com/helyus1412/auth-service/pkg/mailer"
+	"github.com/helyus1412/auth-service/pkg/middleware"
+	"github.com/helyus1412/auth-service/pkg/passwordpolicy"
+	"github.com/helyus1412/auth-service/pkg/ratelimit"
+	"github.com/helyus1412/auth-service/pkg/token"
 	"github.com/jmoiron/sqlx"
 	"github.com/labstack/echo/v4"
 	"go.opentelemetry.io/otel/trace"
 )
 
-func InitRoutes(e *echo.Echo, db *sqlx.DB, tc trace.Tracer, logger *logger.Logger) {
+func InitRoutes(e *echo.Echo, db *sqlx.DB, tc trace.Tracer, logger *logger.Logger, tokenManager *token.Manager, keyUsecase keys.Usecase,
+	cipher *encryption.Cipher, passkeyUsecase passkey.Usecase, limiter ratelimit.Store,
+	tokenDenylist denylist.Denylist, passwordHasher hasher.PasswordHasher, breachChecker breach.Checker) {
 	e.GET("/health-check", func(e echo.Context) error {
 		return e.String(http.StatusOK, "auth service is running properly")
 	})
 
+	refreshRepository := refresh.NewRepository(db, "")
+	refreshUsecase := refresh.NewUsecase(refreshRepository, config.GlobalEnv.RefreshTokenTTL, logger)
+
+	sessionRepository := session.NewRepository(db, "")
+	sessionUsecase := session.NewUsecase(sessionRepository, refreshUsecase, tokenDenylist, session.Config{
+		IdleTTL: config.GlobalEnv.RefreshTokenTTL,
+	}, logger)
+	sessionHandler := session.NewHandler(sessionUsecase, tc)
+
+	rbacRepository := rbac.NewRepository(db, "")
+	rbacUsecase := rbac.NewUsecase(rbacRepository)
+	rbacHandler := rbac.NewHandler(rbacUsecase, tc)
+
+	mailer := newMailer(logger)
+	passwordPolicy := passwordpolicy.New(passwordpolicy.Config{
+		MinLength:       config.GlobalEnv.PasswordMinLength,
+		MaxLength:       config.GlobalEnv.PasswordMaxLength,
+		RequiredClasses: config.GlobalEnv.PasswordClasses,
+		MinScore:        config.GlobalEnv.PasswordMinScore,
+		RejectEmail:     config.GlobalEnv.PasswordRejectEmail,
+		History:         config.GlobalEnv.PasswordHistory,
+	}, passwordHasher, breachChecker)
+
+	mfaRepository := mfa.NewRepository(db, "")
+	mfaUsecase := mfa.NewUsecase(mfaRepository, cipher, config.GlobalEnv.MFAIssuer, logger)
+	mfaHandler := mfa.NewHandler(mfaUsecase, tc)
+
+	passkeyHandler := passkey.NewHandler(passkeyUsecase, tc)
+
+	throttleRepository := throttle.NewRepository(db, "")
+	throttleUsecase := throttle.NewUsecase(throttleRepository, throttle.Config{
+		MaxAttempts:   config.GlobalEnv.LoginMaxAttempts,
+		IPMaxAttempts: config.GlobalEnv.LoginIPMaxAttempts,
+		LockDuration:  config.GlobalEnv.LoginLockDuration,
+		BackoffBase:   config.GlobalEnv.LoginBackoffBase,
+		BackoffMax:    config.GlobalEnv.LoginBackoffMax,
+		Window:        config.GlobalEnv.LoginAttemptWindow,
+	}, logger)
+	throttleHandler := throttle.NewHandler(throttleUsecase, tc)
+
 	authRepository := auth.NewRepository(db, "")
-	authUsecase := auth.NewUsecase(authRepository)
+	authUsecase := auth.NewUsecase(authRepository, rbacRepository, tokenManager, refreshUsecase, sessionUsecase, mfaUsecase,
+		passkeyUsecase, throttleUsecase, passwordHasher, passwordPolicy, mailer, auth.Config{
+			MFATokenTTL:          config.GlobalEnv.MFATokenTTL,
+			VerificationURL:      config.GlobalEnv.EmailVerificationURL,
+			VerificationTTL:      config.GlobalEnv.EmailVerificationTTL,
+			RequireVerifiedEmail: config.GlobalEnv.RequireVerifiedEmail,
+		}, logger)
 	authHandler := auth.NewHandler(authUsecase, tc)
 
-	e.POST("/register", authHandler.Register)
-	e.POST("/login", authHandler.Login)
-	e.GET("/users", authHandler.ListUser)
-	e.PUT("/users/:id", authHandler.Edit)
-	e.DELETE("/users/:id", authHandler.Delete)
+	passwordRepository := password.NewRepository(db, "")
+	passwordUsecase := password.NewUsecase(passwordRepository, authRepository, sessionUsecase, passwordHasher,
+		passwordPolicy, mailer, password.Config{
+			TokenTTL: config.GlobalEnv.PasswordResetTTL,
+			ResetURL: config.GlobalEnv.PasswordResetURL,
+		}, logger)
+	passwordHandler := password.NewHandler(passwordUsecase, tc)
+
+	oauthRepository := oauth.NewRepository(db, "")
+	oauthUsecase := oauth.NewUsecase(oauthRepository, authRepository, rbacRepository, refreshUsecase, tokenManager,
+		tokenDenylist, authUsecase, config.GlobalEnv.OAuthCodeTTL, logger)
+	oauthHandler := oauth.NewHandler(oauthUsecase, tc)
+
+	userImportUsecase := userimport.NewUsecase(authRepository, rbacRepository, passwordHasher, logger)
+	userImportHandler := userimport.NewHandler(userImportUsecase, tc)
+
+	oidcUsecase := oidc.NewUsecase(authRepository, tokenManager)
+	oidcHandler := oidc.NewHandler(oidcUsecase, tc)
+
+	keyHandler := keys.NewHandler(keyUsecase, tc)
+
+	userStatuses := middleware.NewStatusCache(authRepository, config.GlobalEnv.StatusCacheTTL)
+	authenticateAny := middleware.Authenticate(tokenManager, tokenDenylist, userStatuses)
+	firstParty := middleware.FirstParty()
+	// tokens issued to OAuth clients only reach /userinfo, everything else
+	// manages the account and takes a token from /login
+	authenticate := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return authenticateAny(firstParty(next))
+	}
+	requireMFA := middleware.RequireMFA(config.GlobalEnv.MFARequiredRoles)
+
+	rateLimit := func(name string, limit ratelimit.Limit, key middleware.KeyFunc) echo.MiddlewareFunc {
+		return middleware.RateLimit(limiter, logger, middleware.RateLimitConfig{Name: name, Limit: limit, Key: key})
+	}
+
+	// the login endpoints share one bucket so a client can't multiply its
+	// budget by switching between password, mfa and passkey sign in
+	loginLimit := rateLimit("login", ratelimit.PerMinute(30), middleware.KeyByIP)
+	// every request sends an email, the route-wide cap bounds outgoing mail. It
+	// goes after the per-IP limit so one client can't drain it for everyone
+	mailLimit := rateLimit("mail", ratelimit.PerHour(500), middleware.KeyByRoute)
+
+	e.POST("/register", authHandler.Register, rateLimit("register", ratelimit.PerHour(10), middleware.KeyByIP))
+	e.POST("/login", authHandler.Login, loginLimit)
+	e.POST("/login/mfa", authHandler.LoginMFA, loginLimit)
+	e.POST("/login/mfa/passkey", authHandler.LoginMFAPasskey, loginLimit)
+	e.POST("/login/passkey/begin", authHandler.BeginPasskeyLogin, loginLimit)
+	e.POST("/login/passkey/finish", authHandler.LoginPasskey, loginLimit)
+	e.POST("/token/refresh", authHandler.Refresh, rateLimit("refresh", ratelimit.PerMinute(60), middleware.KeyByIP))
+	e.GET("/verify-email", authHandler.VerifyEmail, rateLimit("verify-email", ratelimit.PerMinute(10), middleware.KeyByIP))
+	e.POST("/verify-email/resend", authHandler.ResendVerification, rateLimit("verify-email-resend", ratelimit.PerHour(5), middleware.KeyByIP), mailLimit)
+	e.POST("/password/forgot", passwordHandler.Forgot, rateLimit("password-forgot", ratelimit.PerHour(5), middleware.KeyByIP), mailLimit)
+	e.POST("/logout", sessionHandler.Logout, authenticate)
+	e.POST("/password/reset", passwordHandler.Reset, rateLimit("password-reset", ratelimit.PerHour(10), middleware.KeyByIP))
+
+	mfaRoutes := e.Group("/mfa", authenticate, rateLimit("mfa", ratelimit.PerMinute(10), middleware.KeyByUser))
+	mfaRoutes.POST("/totp/enroll", mfaHandler.Enroll)
+	mfaRoutes.POST("/totp/verify", mfaHandler.Activate)
+	mfaRoutes.DELETE("/totp", mfaHandler.Disable)
+	mfaRoutes.POST("/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+
+	passkeys := e.Group("/passkeys", authenticate)
+	passkeys.GET("", passkeyHandler.ListCredentials)
+	passkeys.POST("/register/begin", passkeyHandler.BeginRegistration)
+	passkeys.POST("/register/finish", passkeyHandler.FinishRegistration)
+	passkeys.DELETE("/:id", passkeyHandler.DeleteCredential)
+
+	sessions := e.Group("/sessions", authenticate)
+	sessions.GET("", sessionHandler.List)
+	sessions.DELETE("", sessionHandler.RevokeAll)
+	sessions.DELETE("/:id", sessionHandler.Revoke)
+
+	users := e.Group("/users", authenticate, requireMFA)
+	users.GET("", authHandler.ListUser, middleware.RequirePermission(rbacRepository, "users:read"))
+	users.GET("/search", authHandler.SearchUsers, middleware.RequirePermission(rbacRepository, "users:read"))
+	users.GET("/:id", authHandler.GetUser, middleware.RequirePermission(rbacRepository, "users:read"))
+	users.PUT("/:id", authHandler.Edit)
+	users.DELETE("/:id", authHandler.Delete, middleware.RequirePermission(rbacRepository, "users:delete"))
+	users.POST("/:id/restore", authHandler.Restore, middleware.RequirePermission(rbacRepository, "users:restore"))
+	users.DELETE("/:id/purge", authHandler.Purge, middleware.RequirePermission(rbacRepository, "users:purge"))
+	users.POST("/:id/suspend", authHandler.Suspend, middleware.RequirePermission(rbacRepository, "users:status"))
+	users.POST("/:id/lock", authHandler.Lock, middleware.RequirePermission(rbacRepository, "users:status"))
+	users.POST("/:id/reactivate", authHandler.Reactivate, middleware.RequirePermission(rbacRepository, "users:status"))
+	users.GET("/:id/status-history", authHandler.StatusHistory, middleware.RequirePermission(rbacRepository, "users:read"))
+	users.POST("/import", userImportHandler.Import, middleware.RequirePermission(rbacRepository, "users:import"))
+	users.POST("/:id/unlock", throttleHandler.Unlock, middleware.RequirePermission(rbacRepository, "users:unlock"))
+	users.POST("/:id/roles", rbacHandler.AssignRole, middleware.RequirePermission(rbacRepository, "roles:manage"))
+	users.DELETE("/:id/roles/:roleId", rbacHandler.RevokeRole, middleware.RequirePermission(rbacRepository, "roles:manage"))
+
+	roles := e.Group("/roles", authenticate, requireMFA)
+	roles.GET("", rbacHandler.ListRoles, middleware.RequirePermission(rbacRepository, "roles:read"))
+	roles.GET("/:id", rbacHandler.GetRole, middleware.RequirePermission(rbacRepository, "roles:read"))
+	roles.POST("", rbacHandler.CreateRole, middleware.RequirePermission(rbacRepository, "roles:manage"))
+	roles.PUT("/:id", rbacHandler.UpdateRole, middleware.RequirePermission(rbacRepository, "roles:manage"))
+	roles.DELETE("/:id", rbacHandler.DeleteRole, middleware.RequirePermission(rbacRepository, "roles:manage"))
+
+	e.GET("/permissions", rbacHandler.ListPermissions, authenticate, requireMFA, middleware.RequirePermission(rbacRepository, "roles:read"))
+
+	e.GET("/oauth/authorize", oauthHandler.Authorize)
+	// approving signs the user in with a password, so it shares the login bucket
+	e.POST("/oauth/authorize", oauthHandler.Approve, loginLimit)
+	e.POST("/oauth/token", oauthHandler.Token, rateLimit("oauth-token", ratelimit.PerMinute(60), middleware.KeyByIP))
+	e.POST("/oauth/revoke", oauthHandler.Revoke, rateLimit("oauth-revoke", ratelimit.PerMinute(60), middleware.KeyByIP))
+	e.POST("/oauth/introspect", oauthHandler.Introspect)
+
+	e.GET("/.well-known/openid-configuration", oidcHandler.Discovery)
+	e.GET("/.well-known/jwks.json", oidcHandler.JWKS)
+	e.GET("/userinfo", oidcHandler.UserInfo, authenticateAny)
+	e.POST("/userinfo", oidcHandler.UserInfo, authenticateAny)
+
+	clients := e.Group("/oauth/clients", authenticate, requireMFA, middleware.RequirePermission(rbacRepository, "clients:manage"))
+	clients.GET("", oauthHandler.ListClients)
+	clients.GET("/:clientId", oauthHandler.GetClient)
+	clients.POST("", oauthHandler.CreateClient)
+	clients.DELETE("/:clientId", oauthHandler.DeleteClient)
+
+	signingKeys := e.Group("/keys", authenticate, requireMFA, middleware.RequirePermission(rbacRepository, "keys:manage"))
+	signingKeys.GET("", keyHandler.ListKeys)
+	signingKeys.POST("/rotate", keyHandler.Rotate)
+}
+
+func newMailer(logger *logger.Logger) mailer.Mailer {
+	switch config.GlobalEnv.MailerDriver {
+	case "smtp":
+		return mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     config.GlobalEnv.SMTPHost,
+			Port:     config.GlobalEnv.SMTPPort,
+			Username: config.GlobalEnv.SMTPUsername,
+			Password: config.GlobalEnv.SMTPPassword,
+			From:     config.GlobalEnv.MailFrom,
+		})
+	case "file":
+		return mailer.NewFileMailer(config.GlobalEnv.MailDir, config.GlobalEnv.MailFrom)
+	default:
+		return mailer.NewLogMailer(logger)
+	}
 }
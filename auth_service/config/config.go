@@ -1,9 +1,16 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -29,6 +36,69 @@ type Env struct {
 	OtelTracePort        int
 	OtelMetricsHost      string
 	OtelMetricsPort      int
+	JWTSigningAlgorithm  string
+	JWTPrivateKeyPath    string
+	JWTIssuer            string
+	JWTAudience          []string
+	JWTAccessTokenTTL    time.Duration
+	RefreshTokenTTL      time.Duration
+	OAuthCodeTTL         time.Duration
+	KeyEncryptionKey     string
+	KeyRotationInterval  time.Duration
+	MFAIssuer            string
+	MFARequiredRoles     []string
+	MFATokenTTL          time.Duration
+	WebAuthnRPID         string
+	WebAuthnRPName       string
+	WebAuthnRPOrigins    []string
+	WebAuthnSessionTTL   time.Duration
+	MailerDriver         string
+	SMTPHost             string
+	SMTPPort             int
+	SMTPUsername         string
+	SMTPPassword         string
+	MailFrom             string
+	MailDir              string
+	PasswordResetURL     string
+	PasswordResetTTL     time.Duration
+	EmailVerificationURL string
+	EmailVerificationTTL time.Duration
+	RequireVerifiedEmail bool
+	BehindProxy          bool
+	LoginMaxAttempts     int
+	LoginIPMaxAttempts   int
+	LoginLockDuration    time.Duration
+	LoginBackoffBase     time.Duration
+	LoginBackoffMax      time.Duration
+	LoginAttemptWindow   time.Duration
+	LoginAttemptCleanup  time.Duration
+	RateLimitBackend     string
+	RedisURL             string
+	TokenDenylistBackend string
+	TokenDenylistCleanup time.Duration
+	PasswordAlgorithm    string
+	BcryptCost           int
+	Argon2Memory         uint32
+	Argon2Iterations     uint32
+	Argon2Parallelism    uint8
+	FirebaseSignerKey    string
+	FirebaseSaltSep      string
+	FirebaseRounds       int
+	FirebaseMemCost      int
+	PasswordMinLength    int
+	PasswordMaxLength    int
+	PasswordClasses      []string
+	PasswordMinScore     int
+	PasswordRejectEmail  bool
+	PasswordHistory      int
+	BreachCheck          string
+	BreachFile           string
+	BreachAPIURL         string
+	BreachAPITimeout     time.Duration
+	DeletedUserGrace     time.Duration
+	DeletedUserAction    string
+	DeletedPurgeInterval time.Duration
+	StatusCacheTTL       time.Duration
 }
 
 func init() {
@@ -140,4 +210,537 @@ func init() {
 	} else {
 		GlobalEnv.OtelMetricsPort = port
 	}
+
+	GlobalEnv.JWTSigningAlgorithm, ok = os.LookupEnv("JWT_SIGNING_ALGORITHM")
+	if !ok {
+		GlobalEnv.JWTSigningAlgorithm = "RS256"
+	}
+
+	// optional: imported as the first signing key when the key table is empty
+	GlobalEnv.JWTPrivateKeyPath, ok = os.LookupEnv("JWT_PRIVATE_KEY_PATH")
+	if !ok {
+		GlobalEnv.JWTPrivateKeyPath = ""
+	}
+
+	GlobalEnv.JWTIssuer, ok = os.LookupEnv("JWT_ISSUER")
+	if !ok {
+		GlobalEnv.JWTIssuer = GlobalEnv.AppName
+	}
+
+	if audience, ok := os.LookupEnv("JWT_AUDIENCE"); ok && audience != "" {
+		GlobalEnv.JWTAudience = strings.Split(audience, ",")
+	}
+
+	if ttl, err := time.ParseDuration(os.Getenv("JWT_ACCESS_TOKEN_TTL")); err != nil {
+		panic("missing or invalid JWT_ACCESS_TOKEN_TTL environment")
+	} else {
+		GlobalEnv.JWTAccessTokenTTL = ttl
+	}
+
+	if ttl, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL")); err != nil {
+		panic("missing or invalid REFRESH_TOKEN_TTL environment")
+	} else {
+		GlobalEnv.RefreshTokenTTL = ttl
+	}
+
+	GlobalEnv.OAuthCodeTTL = time.Minute
+	if ttl, ok := os.LookupEnv("OAUTH_CODE_TTL"); ok {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil {
+			panic("invalid value for OAUTH_CODE_TTL, must be a duration")
+		}
+		GlobalEnv.OAuthCodeTTL = parsed
+	}
+
+	// the key protects the signing keys and TOTP secrets at rest and must come
+	// from the deployment's secret store, never from a committed file
+	GlobalEnv.KeyEncryptionKey = os.Getenv("KEY_ENCRYPTION_KEY")
+	if GlobalEnv.KeyEncryptionKey == "" {
+		if GlobalEnv.IsProduction {
+			log.Panicln("config.init() missing KEY_ENCRYPTION_KEY environment, generate one with: openssl rand -base64 32")
+		}
+
+		key, err := developmentKeyEncryptionKey(developmentKeyEncryptionKeyPath)
+		if err != nil {
+			log.Panicf("config.init() failed to load the development KEY_ENCRYPTION_KEY: %v", err)
+		}
+
+		GlobalEnv.KeyEncryptionKey = key
+		log.Printf("config.init() KEY_ENCRYPTION_KEY is not set, using the development key in %s\n", developmentKeyEncryptionKeyPath)
+	}
+
+	GlobalEnv.KeyRotationInterval = 30 * 24 * time.Hour
+	if interval, ok := os.LookupEnv("KEY_ROTATION_INTERVAL"); ok {
+		parsed, err := time.ParseDuration(interval)
+		if err != nil {
+			panic("invalid value for KEY_ROTATION_INTERVAL, must be a duration")
+		}
+		GlobalEnv.KeyRotationInterval = parsed
+	}
+
+	GlobalEnv.MFAIssuer, ok = os.LookupEnv("MFA_ISSUER")
+	if !ok {
+		GlobalEnv.MFAIssuer = GlobalEnv.AppName
+	}
+
+	// roles that must sign in with a second factor before using privileged routes
+	GlobalEnv.MFARequiredRoles = []string{"admin"}
+	if roles, ok := os.LookupEnv("MFA_REQUIRED_ROLES"); ok {
+		GlobalEnv.MFARequiredRoles = nil
+		if roles != "" {
+			GlobalEnv.MFARequiredRoles = strings.Split(roles, ",")
+		}
+	}
+
+	GlobalEnv.MFATokenTTL = 5 * time.Minute
+	if ttl, ok := os.LookupEnv("MFA_TOKEN_TTL"); ok {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil {
+			panic("invalid value for MFA_TOKEN_TTL, must be a duration")
+		}
+		GlobalEnv.MFATokenTTL = parsed
+	}
+
+	GlobalEnv.WebAuthnRPID, ok = os.LookupEnv("WEBAUTHN_RP_ID")
+	if !ok {
+		log.Panicln("config.init() missing WEBAUTHN_RP_ID environment")
+	}
+
+	GlobalEnv.WebAuthnRPName, ok = os.LookupEnv("WEBAUTHN_RP_NAME")
+	if !ok {
+		GlobalEnv.WebAuthnRPName = GlobalEnv.AppName
+	}
+
+	origins, ok := os.LookupEnv("WEBAUTHN_RP_ORIGINS")
+	if !ok || origins == "" {
+		log.Panicln("config.init() missing WEBAUTHN_RP_ORIGINS environment")
+	}
+	GlobalEnv.WebAuthnRPOrigins = strings.Split(origins, ",")
+
+	GlobalEnv.WebAuthnSessionTTL = 5 * time.Minute
+	if ttl, ok := os.LookupEnv("WEBAUTHN_SESSION_TTL"); ok {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil {
+			panic("invalid value for WEBAUTHN_SESSION_TTL, must be a duration")
+		}
+		GlobalEnv.WebAuthnSessionTTL = parsed
+	}
+
+	// smtp delivers for real; file and log are meant for local development
+	GlobalEnv.MailerDriver = "log"
+	if driver, ok := os.LookupEnv("MAILER_DRIVER"); ok && driver != "" {
+		GlobalEnv.MailerDriver = driver
+	}
+
+	switch GlobalEnv.MailerDriver {
+	case "smtp":
+		GlobalEnv.SMTPHost, ok = os.LookupEnv("SMTP_HOST")
+		if !ok {
+			log.Panicln("config.init() missing SMTP_HOST environment")
+		}
+
+		if port, err := strconv.Atoi(os.Getenv("SMTP_PORT")); err != nil {
+			panic("missing SMTP_PORT environment")
+		} else {
+			GlobalEnv.SMTPPort = port
+		}
+
+		GlobalEnv.SMTPUsername = os.Getenv("SMTP_USERNAME")
+		GlobalEnv.SMTPPassword = os.Getenv("SMTP_PASSWORD")
+	case "file", "log":
+	default:
+		panic("invalid value for MAILER_DRIVER, must be smtp, file or log")
+	}
+
+	GlobalEnv.MailFrom, ok = os.LookupEnv("MAIL_FROM")
+	if !ok {
+		GlobalEnv.MailFrom = "no-reply@localhost"
+	}
+
+	GlobalEnv.MailDir, ok = os.LookupEnv("MAIL_DIR")
+	if !ok {
+		GlobalEnv.MailDir = "mail"
+	}
+
+	GlobalEnv.PasswordResetURL, ok = os.LookupEnv("PASSWORD_RESET_URL")
+	if !ok {
+		log.Panicln("config.init() missing PASSWORD_RESET_URL environment")
+	}
+
+	GlobalEnv.PasswordResetTTL = time.Hour
+	if ttl, ok := os.LookupEnv("PASSWORD_RESET_TTL"); ok {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil {
+			panic("invalid value for PASSWORD_RESET_TTL, must be a duration")
+		}
+		GlobalEnv.PasswordResetTTL = parsed
+	}
+
+	GlobalEnv.EmailVerificationURL, ok = os.LookupEnv("EMAIL_VERIFICATION_URL")
+	if !ok {
+		log.Panicln("config.init() missing EMAIL_VERIFICATION_URL environment")
+	}
+
+	GlobalEnv.EmailVerificationTTL = 24 * time.Hour
+	if ttl, ok := os.LookupEnv("EMAIL_VERIFICATION_TTL"); ok {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil {
+			panic("invalid value for EMAIL_VERIFICATION_TTL, must be a duration")
+		}
+		GlobalEnv.EmailVerificationTTL = parsed
+	}
+
+	// accounts created before verification existed start out unverified, so
+	// only turn this on once they had a chance to verify
+	if require, ok := os.LookupEnv("REQUIRE_VERIFIED_EMAIL"); ok {
+		parsed, err := strconv.ParseBool(require)
+		if err != nil {
+			panic("invalid value for REQUIRE_VERIFIED_EMAIL, must be true or false")
+		}
+		GlobalEnv.RequireVerifiedEmail = parsed
+	}
+
+	// client addresses are only taken from X-Forwarded-For behind a trusted proxy
+	if behindProxy, ok := os.LookupEnv("BEHIND_PROXY"); ok {
+		parsed, err := strconv.ParseBool(behindProxy)
+		if err != nil {
+			panic("invalid value for BEHIND_PROXY, must be true or false")
+		}
+		GlobalEnv.BehindProxy = parsed
+	}
+
+	GlobalEnv.LoginMaxAttempts = 5
+	if attempts, ok := os.LookupEnv("LOGIN_MAX_ATTEMPTS"); ok {
+		parsed, err := strconv.Atoi(attempts)
+		if err != nil || parsed < 1 {
+			panic("invalid value for LOGIN_MAX_ATTEMPTS, must be a positive number")
+		}
+		GlobalEnv.LoginMaxAttempts = parsed
+	}
+
+	GlobalEnv.LoginIPMaxAttempts = 20
+	if attempts, ok := os.LookupEnv("LOGIN_IP_MAX_ATTEMPTS"); ok {
+		parsed, err := strconv.Atoi(attempts)
+		if err != nil || parsed < 1 {
+			panic("invalid value for LOGIN_IP_MAX_ATTEMPTS, must be a positive number")
+		}
+		GlobalEnv.LoginIPMaxAttempts = parsed
+	}
+
+	GlobalEnv.LoginLockDuration = 15 * time.Minute
+	if duration, ok := os.LookupEnv("LOGIN_LOCK_DURATION"); ok {
+		parsed, err := time.ParseDuration(duration)
+		if err != nil {
+			panic("invalid value for LOGIN_LOCK_DURATION, must be a duration")
+		}
+		GlobalEnv.LoginLockDuration = parsed
+	}
+
+	GlobalEnv.LoginBackoffBase = time.Second
+	if duration, ok := os.LookupEnv("LOGIN_BACKOFF_BASE"); ok {
+		parsed, err := time.ParseDuration(duration)
+		if err != nil {
+			panic("invalid value for LOGIN_BACKOFF_BASE, must be a duration")
+		}
+		GlobalEnv.LoginBackoffBase = parsed
+	}
+
+	GlobalEnv.LoginBackoffMax = time.Minute
+	if duration, ok := os.LookupEnv("LOGIN_BACKOFF_MAX"); ok {
+		parsed, err := time.ParseDuration(duration)
+		if err != nil {
+			panic("invalid value for LOGIN_BACKOFF_MAX, must be a duration")
+		}
+		GlobalEnv.LoginBackoffMax = parsed
+	}
+
+	GlobalEnv.LoginAttemptWindow = 15 * time.Minute
+	if duration, ok := os.LookupEnv("LOGIN_ATTEMPT_WINDOW"); ok {
+		parsed, err := time.ParseDuration(duration)
+		if err != nil {
+			panic("invalid value for LOGIN_ATTEMPT_WINDOW, must be a duration")
+		}
+		GlobalEnv.LoginAttemptWindow = parsed
+	}
+
+	GlobalEnv.LoginAttemptCleanup = time.Hour
+	if interval, ok := os.LookupEnv("LOGIN_ATTEMPT_CLEANUP_INTERVAL"); ok {
+		parsed, err := time.ParseDuration(interval)
+		if err != nil || parsed <= 0 {
+			panic("invalid value for LOGIN_ATTEMPT_CLEANUP_INTERVAL, must be a positive duration")
+		}
+		GlobalEnv.LoginAttemptCleanup = parsed
+	}
+
+	// redis shares the limits between replicas, memory counts per process
+	GlobalEnv.RateLimitBackend = "memory"
+	if backend, ok := os.LookupEnv("RATE_LIMIT_BACKEND"); ok && backend != "" {
+		GlobalEnv.RateLimitBackend = backend
+	}
+
+	switch GlobalEnv.RateLimitBackend {
+	case "redis":
+		GlobalEnv.RedisURL, ok = os.LookupEnv("REDIS_URL")
+		if !ok {
+			log.Panicln("config.init() missing REDIS_URL environment")
+		}
+	case "memory":
+	default:
+		panic("invalid value for RATE_LIMIT_BACKEND, must be memory or redis")
+	}
+
+	// memory loses revocations on restart and does not share them between replicas
+	GlobalEnv.TokenDenylistBackend = "postgres"
+	if backend, ok := os.LookupEnv("TOKEN_DENYLIST_BACKEND"); ok && backend != "" {
+		GlobalEnv.TokenDenylistBackend = backend
+	}
+
+	if GlobalEnv.TokenDenylistBackend != "postgres" && GlobalEnv.TokenDenylistBackend != "memory" {
+		panic("invalid value for TOKEN_DENYLIST_BACKEND, must be postgres or memory")
+	}
+
+	GlobalEnv.TokenDenylistCleanup = 10 * time.Minute
+	if interval, ok := os.LookupEnv("TOKEN_DENYLIST_CLEANUP_INTERVAL"); ok {
+		parsed, err := time.ParseDuration(interval)
+		if err != nil || parsed <= 0 {
+			panic("invalid value for TOKEN_DENYLIST_CLEANUP_INTERVAL, must be a positive duration")
+		}
+		GlobalEnv.TokenDenylistCleanup = parsed
+	}
+
+	// hashes of the other algorithm keep working and are upgraded on login
+	GlobalEnv.PasswordAlgorithm = "argon2id"
+	if algorithm, ok := os.LookupEnv("PASSWORD_HASH_ALGORITHM"); ok && algorithm != "" {
+		GlobalEnv.PasswordAlgorithm = algorithm
+	}
+
+	if GlobalEnv.PasswordAlgorithm != "argon2id" && GlobalEnv.PasswordAlgorithm != "bcrypt" {
+		panic("invalid value for PASSWORD_HASH_ALGORITHM, must be argon2id or bcrypt")
+	}
+
+	GlobalEnv.BcryptCost = 12
+	if cost, ok := os.LookupEnv("BCRYPT_COST"); ok {
+		parsed, err := strconv.Atoi(cost)
+		if err != nil || parsed < 10 || parsed > 14 {
+			panic("invalid value for BCRYPT_COST, must be a number between 10 and 14")
+		}
+		GlobalEnv.BcryptCost = parsed
+	}
+
+	GlobalEnv.Argon2Memory = 64 * 1024
+	if memory, ok := os.LookupEnv("ARGON2_MEMORY"); ok {
+		parsed, err := strconv.ParseUint(memory, 10, 32)
+		if err != nil || parsed < 19*1024 {
+			panic("invalid value for ARGON2_MEMORY, must be at least 19456 KiB")
+		}
+		GlobalEnv.Argon2Memory = uint32(parsed)
+	}
+
+	GlobalEnv.Argon2Iterations = 3
+	if iterations, ok := os.LookupEnv("ARGON2_ITERATIONS"); ok {
+		parsed, err := strconv.ParseUint(iterations, 10, 32)
+		if err != nil || parsed < 1 {
+			panic("invalid value for ARGON2_ITERATIONS, must be a positive number")
+		}
+		GlobalEnv.Argon2Iterations = uint32(parsed)
+	}
+
+	GlobalEnv.Argon2Parallelism = 2
+	if parallelism, ok := os.LookupEnv("ARGON2_PARALLELISM"); ok {
+		parsed, err := strconv.ParseUint(parallelism, 10, 8)
+		if err != nil || parsed < 1 {
+			panic("invalid value for ARGON2_PARALLELISM, must be a number between 1 and 255")
+		}
+		GlobalEnv.Argon2Parallelism = uint8(parsed)
+	}
+
+	// only needed to verify users imported from Firebase Authentication
+	GlobalEnv.FirebaseSignerKey = os.Getenv("FIREBASE_SIGNER_KEY")
+	GlobalEnv.FirebaseSaltSep = os.Getenv("FIREBASE_SALT_SEPARATOR")
+
+	GlobalEnv.FirebaseRounds = 8
+	if rounds, ok := os.LookupEnv("FIREBASE_ROUNDS"); ok {
+		parsed, err := strconv.Atoi(rounds)
+		if err != nil {
+			panic("invalid value for FIREBASE_ROUNDS, must be a number")
+		}
+		GlobalEnv.FirebaseRounds = parsed
+	}
+
+	GlobalEnv.FirebaseMemCost = 14
+	if memCost, ok := os.LookupEnv("FIREBASE_MEM_COST"); ok {
+		parsed, err := strconv.Atoi(memCost)
+		if err != nil {
+			panic("invalid value for FIREBASE_MEM_COST, must be a number")
+		}
+		GlobalEnv.FirebaseMemCost = parsed
+	}
+
+	GlobalEnv.PasswordMinLength = 12
+	if length, ok := os.LookupEnv("PASSWORD_MIN_LENGTH"); ok {
+		parsed, err := strconv.Atoi(length)
+		if err != nil || parsed < 1 {
+			panic("invalid value for PASSWORD_MIN_LENGTH, must be a positive number")
+		}
+		GlobalEnv.PasswordMinLength = parsed
+	}
+
+	// bcrypt ignores everything past 72 bytes, keep the limit below that when
+	// it is the configured algorithm
+	GlobalEnv.PasswordMaxLength = 128
+	if length, ok := os.LookupEnv("PASSWORD_MAX_LENGTH"); ok {
+		parsed, err := strconv.Atoi(length)
+		if err != nil || parsed < GlobalEnv.PasswordMinLength {
+			panic("invalid value for PASSWORD_MAX_LENGTH, must be a number not below PASSWORD_MIN_LENGTH")
+		}
+		GlobalEnv.PasswordMaxLength = parsed
+	}
+
+	// comma separated subset of lower, upper, digit and symbol
+	if classes, ok := os.LookupEnv("PASSWORD_REQUIRED_CLASSES"); ok && classes != "" {
+		for _, class := range strings.Split(classes, ",") {
+			class = strings.TrimSpace(class)
+			if class != "lower" && class != "upper" && class != "digit" && class != "symbol" {
+				panic("invalid value for PASSWORD_REQUIRED_CLASSES, must be a list of lower, upper, digit and symbol")
+			}
+			GlobalEnv.PasswordClasses = append(GlobalEnv.PasswordClasses, class)
+		}
+	}
+
+	GlobalEnv.PasswordMinScore = 3
+	if score, ok := os.LookupEnv("PASSWORD_MIN_SCORE"); ok {
+		parsed, err := strconv.Atoi(score)
+		if err != nil || parsed < 0 || parsed > 4 {
+			panic("invalid value for PASSWORD_MIN_SCORE, must be a number between 0 and 4")
+		}
+		GlobalEnv.PasswordMinScore = parsed
+	}
+
+	GlobalEnv.PasswordRejectEmail = true
+	if reject, ok := os.LookupEnv("PASSWORD_REJECT_EMAIL"); ok {
+		parsed, err := strconv.ParseBool(reject)
+		if err != nil {
+			panic("invalid value for PASSWORD_REJECT_EMAIL, must be true or false")
+		}
+		GlobalEnv.PasswordRejectEmail = parsed
+	}
+
+	// number of previous passwords that may not be reused, 0 disables the check
+	GlobalEnv.PasswordHistory = 5
+	if history, ok := os.LookupEnv("PASSWORD_HISTORY"); ok {
+		parsed, err := strconv.Atoi(history)
+		if err != nil || parsed < 0 {
+			panic("invalid value for PASSWORD_HISTORY, must be a number")
+		}
+		GlobalEnv.PasswordHistory = parsed
+	}
+
+	// off, file (a dataset written by cmd/breachdb) or api (a Pwned Passwords
+	// compatible range API)
+	GlobalEnv.BreachCheck = "off"
+	if check, ok := os.LookupEnv("BREACH_CHECK"); ok && check != "" {
+		GlobalEnv.BreachCheck = check
+	}
+	if GlobalEnv.BreachCheck != "off" && GlobalEnv.BreachCheck != "file" && GlobalEnv.BreachCheck != "api" {
+		panic("invalid value for BREACH_CHECK, must be off, file or api")
+	}
+
+	GlobalEnv.BreachFile = os.Getenv("BREACH_FILE")
+	if GlobalEnv.BreachCheck == "file" && GlobalEnv.BreachFile == "" {
+		log.Panicln("config.init() missing BREACH_FILE environment")
+	}
+
+	GlobalEnv.BreachAPIURL = "https://api.pwnedpasswords.com"
+	if url, ok := os.LookupEnv("BREACH_API_URL"); ok && url != "" {
+		GlobalEnv.BreachAPIURL = url
+	}
+
+	GlobalEnv.BreachAPITimeout = 2 * time.Second
+	if timeout, ok := os.LookupEnv("BREACH_API_TIMEOUT"); ok {
+		parsed, err := time.ParseDuration(timeout)
+		if err != nil || parsed <= 0 {
+			panic("invalid value for BREACH_API_TIMEOUT, must be a positive duration")
+		}
+		GlobalEnv.BreachAPITimeout = parsed
+	}
+
+	// deleted users can be restored for this long, 0 keeps them forever
+	GlobalEnv.DeletedUserGrace = 30 * 24 * time.Hour
+	if grace, ok := os.LookupEnv("DELETED_USER_GRACE_PERIOD"); ok {
+		parsed, err := time.ParseDuration(grace)
+		if err != nil || parsed < 0 {
+			panic("invalid value for DELETED_USER_GRACE_PERIOD, must be a duration")
+		}
+		GlobalEnv.DeletedUserGrace = parsed
+	}
+
+	GlobalEnv.DeletedUserAction = "anonymize"
+	if action, ok := os.LookupEnv("DELETED_USER_ACTION"); ok && action != "" {
+		GlobalEnv.DeletedUserAction = action
+	}
+	if GlobalEnv.DeletedUserAction != "anonymize" && GlobalEnv.DeletedUserAction != "purge" {
+		panic("invalid value for DELETED_USER_ACTION, must be anonymize or purge")
+	}
+
+	GlobalEnv.DeletedPurgeInterval = time.Hour
+	if interval, ok := os.LookupEnv("DELETED_USER_PURGE_INTERVAL"); ok {
+		parsed, err := time.ParseDuration(interval)
+		if err != nil || parsed <= 0 {
+			panic("invalid value for DELETED_USER_PURGE_INTERVAL, must be a positive duration")
+		}
+		GlobalEnv.DeletedPurgeInterval = parsed
+	}
+
+	// bounds how long suspending or locking a user takes to reject the access
+	// tokens issued before
+	GlobalEnv.StatusCacheTTL = 10 * time.Second
+	if ttl, ok := os.LookupEnv("USER_STATUS_CACHE_TTL"); ok {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil || parsed < 0 {
+			panic("invalid value for USER_STATUS_CACHE_TTL, must be a duration")
+		}
+		GlobalEnv.StatusCacheTTL = parsed
+	}
+}
+
+// developmentKeyEncryptionKeyPath lives under the gitignored keys/ directory so
+// a development key survives restarts without ever being committed.
+const developmentKeyEncryptionKeyPath = "keys/key-encryption-key"
+
+// developmentKeyEncryptionKey reads the development key at path, creating it
+// on first use.
+func developmentKeyEncryptionKey(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err == nil {
+		return strings.TrimSpace(string(content)), nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	key := base64.StdEncoding.EncodeToString(raw)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+	// O_EXCL keeps a concurrently started instance from overwriting a key
+	// that may already protect data
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, fs.ErrExist) {
+		return developmentKeyEncryptionKey(path)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(key + "\n"); err != nil {
+		return "", err
+	}
+
+	return key, nil
 }
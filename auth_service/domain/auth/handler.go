@@ -13,9 +13,24 @@ import (
 type Handler interface {
 	Register(c echo.Context) error
 	Login(c echo.Context) error
+	LoginMFA(c echo.Context) error
+	LoginMFAPasskey(c echo.Context) error
+	BeginPasskeyLogin(c echo.Context) error
+	LoginPasskey(c echo.Context) error
+	Refresh(c echo.Context) error
+	VerifyEmail(c echo.Context) error
+	ResendVerification(c echo.Context) error
 	ListUser(c echo.Context) error
+	SearchUsers(c echo.Context) error
+	GetUser(c echo.Context) error
 	Edit(c echo.Context) error
 	Delete(c echo.Context) error
+	Restore(c echo.Context) error
+	Purge(c echo.Context) error
+	Suspend(c echo.Context) error
+	Lock(c echo.Context) error
+	Reactivate(c echo.Context) error
+	StatusHistory(c echo.Context) error
 }
 
 type handler struct {
@@ -57,6 +72,9 @@ func (h *handler) Login(c echo.Context) error {
 		return utils.ResponseError(respErr, c)
 	}
 
+	payload.IP = c.RealIP()
+	payload.UserAgent = c.Request().UserAgent()
+
 	result := h.usecase.Login(ctx, &payload)
 	if result.Error != nil {
 		return utils.ResponseError(result.Error, c)
@@ -65,16 +83,195 @@ func (h *handler) Login(c echo.Context) error {
 	return utils.Response(result.Data, "Success Login", http.StatusOK, c)
 }
 
+func (h *handler) LoginMFA(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.LoginMFA")
+	defer span.End()
+
+	var payload dto.LoginMFARequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	payload.IP = c.RealIP()
+	payload.UserAgent = c.Request().UserAgent()
+
+	result := h.usecase.LoginMFA(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Login", http.StatusOK, c)
+}
+
+func (h *handler) LoginMFAPasskey(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.LoginMFAPasskey")
+	defer span.End()
+
+	var payload dto.LoginMFAPasskeyRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.LoginMFAPasskey(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Passkey Login Options", http.StatusOK, c)
+}
+
+func (h *handler) BeginPasskeyLogin(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.BeginPasskeyLogin")
+	defer span.End()
+
+	result := h.usecase.BeginPasskeyLogin(ctx)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Passkey Login Options", http.StatusOK, c)
+}
+
+func (h *handler) LoginPasskey(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.LoginPasskey")
+	defer span.End()
+
+	var payload dto.PasskeyFinishRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	payload.IP = c.RealIP()
+	payload.UserAgent = c.Request().UserAgent()
+
+	result := h.usecase.LoginPasskey(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Login", http.StatusOK, c)
+}
+
+func (h *handler) Refresh(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Refresh")
+	defer span.End()
+
+	var payload dto.RefreshTokenRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	payload.IP = c.RealIP()
+
+	result := h.usecase.Refresh(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Refresh Token", http.StatusOK, c)
+}
+
+func (h *handler) VerifyEmail(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.VerifyEmail")
+	defer span.End()
+
+	var payload dto.VerifyEmailRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.VerifyEmail(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Verify Email", http.StatusOK, c)
+}
+
+func (h *handler) ResendVerification(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.ResendVerification")
+	defer span.End()
+
+	var payload dto.ResendVerificationRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.ResendVerification(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "If the address is registered and unverified, a new link has been sent", http.StatusOK, c)
+}
+
 func (h *handler) ListUser(c echo.Context) error {
 	ctx, span := h.tc.Start(c.Request().Context(), "handler.ListUser")
 	defer span.End()
 
-	result := h.usecase.ListUser(ctx)
+	var payload dto.ListUsersRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.ListUser(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.PaginationResponse(result.Data, result.MetaData, "List User", http.StatusOK, c)
+}
+
+func (h *handler) SearchUsers(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.SearchUsers")
+	defer span.End()
+
+	var payload dto.SearchUsersRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.SearchUsers(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.PaginationResponse(result.Data, result.MetaData, "Search User", http.StatusOK, c)
+}
+
+func (h *handler) GetUser(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.GetUser")
+	defer span.End()
+
+	var payload dto.DeleteRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.GetUser(ctx, payload.ID)
 	if result.Error != nil {
 		return utils.ResponseError(result.Error, c)
 	}
 
-	return utils.Response(result.Data, "List User", http.StatusOK, c)
+	return utils.Response(result.Data, "Success Get User", http.StatusOK, c)
 }
 
 func (h *handler) Edit(c echo.Context) error {
@@ -114,3 +311,117 @@ func (h *handler) Delete(c echo.Context) error {
 
 	return utils.Response(result.Data, "Success Delete User", http.StatusOK, c)
 }
+
+func (h *handler) Restore(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Restore")
+	defer span.End()
+
+	var payload dto.DeleteRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.Restore(ctx, payload.ID)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Restore User", http.StatusOK, c)
+}
+
+func (h *handler) Purge(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Purge")
+	defer span.End()
+
+	var payload dto.DeleteRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.Purge(ctx, payload.ID)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Purge User", http.StatusOK, c)
+}
+
+func (h *handler) Suspend(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Suspend")
+	defer span.End()
+
+	var payload dto.UserStatusRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.Suspend(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Suspend User", http.StatusOK, c)
+}
+
+func (h *handler) Lock(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Lock")
+	defer span.End()
+
+	var payload dto.UserStatusRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.Lock(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Lock User", http.StatusOK, c)
+}
+
+func (h *handler) Reactivate(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Reactivate")
+	defer span.End()
+
+	var payload dto.UserStatusRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.Reactivate(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Reactivate User", http.StatusOK, c)
+}
+
+func (h *handler) StatusHistory(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.StatusHistory")
+	defer span.End()
+
+	var payload dto.DeleteRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.StatusHistory(ctx, payload.ID)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Get User Status History", http.StatusOK, c)
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/helyus1412/auth-service/model"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// userCursor points behind the last user of a page. Sort is kept so that a
+// cursor is not reused with another order, where its value means nothing.
+type userCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int64  `json:"i"`
+}
+
+// parseUserSort turns "created_at" or "-created_at" into a page order,
+// defaulting to ascending ids.
+func parseUserSort(sort string) (UserPage, bool) {
+	if sort == "" {
+		return UserPage{SortBy: "id"}, true
+	}
+
+	field, desc := strings.CutPrefix(sort, "-")
+	if !slices.Contains(UserSortFields, field) {
+		return UserPage{}, false
+	}
+
+	return UserPage{SortBy: field, Desc: desc}, true
+}
+
+func encodeUserCursor(sort string, user model.User) string {
+	cursor := userCursor{Sort: sort, ID: user.ID}
+
+	switch strings.TrimPrefix(sort, "-") {
+	case "email":
+		cursor.Value = user.Email
+	case "created_at":
+		cursor.Value = user.CreatedAt.Format(time.RFC3339Nano)
+	default:
+		cursor.Value = strconv.FormatInt(user.ID, 10)
+	}
+
+	raw, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeUserCursor(sort string, encoded string) (*UserKey, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var cursor userCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.Sort != sort {
+		return nil, errInvalidCursor
+	}
+
+	return &UserKey{Value: cursor.Value, ID: cursor.ID}, nil
+}
+
+// parseCreatedBound accepts an RFC 3339 time or a date. A date given as the
+// end of the range covers the whole day.
+func parseCreatedBound(raw string, end bool) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		t = t.UTC()
+		return &t, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if end {
+		// timestamps are stored with microsecond precision
+		t = t.AddDate(0, 0, 1).Add(-time.Microsecond)
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/helyus1412/auth-service/model"
+	"github.com/helyus1412/auth-service/pkg/hasher"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"github.com/helyus1412/auth-service/pkg/passwordpolicy"
+	"github.com/helyus1412/auth-service/pkg/principal"
+	"go.uber.org/zap"
+)
+
+// VerifyPassword reports whether password matches the user's stored hash. On a
+// match a hash made with an outdated algorithm or weaker parameters is
+// replaced, a failure to do so is logged and does not fail the login.
+func VerifyPassword(ctx context.Context, repository Repository, passwordHasher hasher.PasswordHasher, logger *logger.Logger,
+	user *model.User, password string) bool {
+	ok, err := passwordHasher.Verify(user.Password, password)
+	if err != nil {
+		logger.Error(ctx, "auth.VerifyPassword", "Verify", "stored password hash could not be verified", err,
+			zap.Int64("user_id", user.ID))
+		return false
+	}
+
+	if !ok || !passwordHasher.NeedsRehash(user.Password) {
+		return ok
+	}
+
+	rehashed, err := passwordHasher.Hash(password)
+	if err == nil {
+		err = repository.UpdatePassword(user.ID, rehashed, principal.ActorSystem)
+	}
+	if err != nil {
+		logger.Error(ctx, "auth.VerifyPassword", "Rehash", "failed to upgrade password hash", err,
+			zap.Int64("user_id", user.ID))
+		return true
+	}
+
+	user.Password = rehashed
+
+	return true
+}
+
+// HashError maps a failure to hash a new password to a response error.
+func HashError(err error) interface{} {
+	switch {
+	case errors.Is(err, hasher.ErrEmptyPassword):
+		return httpError.NewBadRequest("password is required")
+	case errors.Is(err, hasher.ErrPasswordTooLong):
+		return httpError.NewBadRequest("password is too long")
+	default:
+		return httpError.NewInternalServerError(err.Error())
+	}
+}
+
+// CheckPassword applies policy to password as the new password of user,
+// whose ID is zero while registering. It returns nil when the password is
+// acceptable and otherwise the response error listing every broken rule. A
+// breach lookup that fails is logged and skipped rather than blocking every
+// password change while the dataset is unavailable.
+func CheckPassword(ctx context.Context, repository Repository, policy *passwordpolicy.Policy, logger *logger.Logger,
+	user *model.User, password string) interface{} {
+	var previous []string
+	if user.ID != 0 && policy.History() > 0 {
+		previous = []string{user.Password}
+	}
+
+	if user.ID != 0 && policy.History() > 1 {
+		history, err := repository.GetPasswordHistory(user.ID, policy.History()-1)
+		if err != nil {
+			return httpError.NewInternalServerError(err.Error())
+		}
+
+		previous = append(previous, history...)
+	}
+
+	violations, err := policy.Check(ctx, password, user.Email, previous)
+	if err != nil {
+		logger.Error(ctx, "auth.CheckPassword", "Breached", "failed to look up password in breach corpus", err,
+			zap.Int64("user_id", user.ID))
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	errs := make([]httpError.FieldError, 0, len(violations))
+	for _, violation := range violations {
+		errs = append(errs, httpError.FieldError{Field: "password", Rule: violation.Rule, Message: violation.Message})
+	}
+
+	return httpError.NewValidationError("PASSWORD-POLICY", "password does not meet the password policy", errs)
+}
+
+// RecordPassword keeps the hash a password change replaced so that it cannot
+// be reused. The current hash counts towards the history, so only one less is
+// kept. A failure is logged and does not undo the change.
+func RecordPassword(ctx context.Context, repository Repository, policy *passwordpolicy.Policy, logger *logger.Logger,
+	userID int64, replaced string) {
+	if policy.History() <= 1 {
+		return
+	}
+
+	if err := repository.AddPasswordHistory(userID, replaced, policy.History()-1); err != nil {
+		logger.Error(ctx, "auth.RecordPassword", "AddPasswordHistory", "failed to record password history", err,
+			zap.Int64("user_id", userID))
+	}
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type PurgeConfig struct {
+	// GracePeriod is how long a deleted user can still be restored.
+	GracePeriod time.Duration
+	// Anonymize erases the personal data of expired users instead of
+	// removing their rows.
+	Anonymize bool
+	Interval  time.Duration
+}
+
+// RunDeletedPurge removes or anonymizes the users deleted longer than the
+// grace period ago, every interval until ctx is done.
+func RunDeletedPurge(ctx context.Context, repository Repository, config PurgeConfig, logger *logger.Logger) {
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			before := time.Now().Add(-config.GracePeriod)
+
+			action, expire := "purged", repository.PurgeDeleted
+			if config.Anonymize {
+				action, expire = "anonymized", repository.AnonymizeDeleted
+			}
+
+			expired, err := expire(before)
+			if err != nil {
+				logger.Error(ctx, "auth.RunDeletedPurge", "Expire", "failed to expire deleted users", err)
+				continue
+			}
+
+			if expired > 0 {
+				logger.Info(ctx, "auth.RunDeletedPurge", "Expire", action+" deleted users", zap.Int64(action, expired))
+			}
+		}
+	}
+}
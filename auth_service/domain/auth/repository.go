@@ -1,22 +1,123 @@
 package auth
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/helyus1412/auth-service/model"
+	"github.com/helyus1412/auth-service/pkg/principal"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// Lookups skip soft-deleted users unless their name says otherwise.
 type Repository interface {
-	Insert(*model.User) error
+	// Insert, Update and UpdatePassword record actor in created_by or
+	// updated_by, see principal.ActorFrom.
+	Insert(user *model.User, actor string) error
 	GetByEmail(string) (*model.User, error)
-	GetAll() ([]model.User, error)
-	Update(*model.User) error
+	List(UserFilter, UserPage) ([]model.User, error)
+	Count(UserFilter) (int64, error)
+	// Search ranks the users matching query and returns one page of them with
+	// the total number of matches.
+	Search(UserSearch) ([]UserMatch, int64, error)
+	Update(user *model.User, actor string) error
 	GetByID(int64) (*model.User, error)
-	SoftDelete(int64) error
+	GetByIDWithDeleted(int64) (*model.User, error)
+	// GetStatus returns the status of the user, deleted ones included, or ""
+	// when there is no such user.
+	GetStatus(int64) (string, error)
+	// UpdateStatus moves a user from change.FromStatus to change.ToStatus and
+	// records the change with change.Actor as updated_by. Entering or leaving
+	// the deleted status also sets or clears deleted_at and deleted_by. It reports false when the user is no
+	// longer in FromStatus or, when leaving deleted, was anonymized.
+	UpdateStatus(change *model.UserStatusChange) (bool, error)
+	GetStatusChanges(userID int64) ([]model.UserStatusChange, error)
+	// Purge removes the user and, through the foreign keys, everything that
+	// belongs to it.
+	Purge(int64) error
+	// PurgeDeleted removes the users deleted before the given time.
+	PurgeDeleted(time.Time) (int64, error)
+	// AnonymizeDeleted erases the personal data and credentials of the users
+	// deleted before the given time, keeping the rows for the audit trail.
+	AnonymizeDeleted(time.Time) (int64, error)
+	UpdatePassword(id int64, password string, actor string) error
+	// MarkEmailVerified records that the user proved ownership of email. It
+	// reports false when the account's address has changed since.
+	MarkEmailVerified(id int64, email string) (bool, error)
+	// GetPasswordHistory returns up to limit replaced password hashes, newest
+	// first.
+	GetPasswordHistory(userID int64, limit int) ([]string, error)
+	// AddPasswordHistory records a replaced password hash and drops all but the
+	// newest keep entries.
+	AddPasswordHistory(userID int64, password string, keep int) error
 }
 
+// UserSortFields are the columns users may be sorted by.
+var UserSortFields = []string{"id", "email", "created_at"}
+
+// UserFilter narrows down List and Count. Both ends of the created range are
+// inclusive.
+type UserFilter struct {
+	EmailContains  string
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	IncludeDeleted bool
+}
+
+// UserPage selects one page of List. Rows are ordered by SortBy, then by id.
+type UserPage struct {
+	SortBy string
+	Desc   bool
+	Limit  int
+	Offset int
+	// After continues right behind the given row instead of skipping Offset
+	// rows, so concurrent inserts and deletes do not shift the page.
+	After *UserKey
+}
+
+// UserKey identifies a row in the order of UserPage.SortBy. Value is the row's
+// SortBy column in its text form.
+type UserKey struct {
+	Value string
+	ID    int64
+}
+
+// UserSearch selects one page of Search results.
+type UserSearch struct {
+	Query string
+	// Terms are the words of Query, each matched as a prefix.
+	Terms          []string
+	IncludeDeleted bool
+	Limit          int
+	Offset         int
+}
+
+// UserMatch is a user found by Search with its relevance.
+type UserMatch struct {
+	model.User
+	Score float64 `db:"score"`
+}
+
+// The search expressions match the indexes of the add_users_search_indexes
+// migration, Postgres only uses an expression index for the same expression.
+const (
+	userAuditColumns   = `(coalesce(created_by, '') || ' ' || coalesce(updated_by, '') || ' ' || coalesce(deleted_by, ''))`
+	userSearchDocument = `(setweight(to_tsvector('simple', regexp_replace(email, '[@._+-]+', ' ', 'g')), 'A') ||
+    setweight(to_tsvector('simple', regexp_replace(
+        coalesce(created_by, '') || ' ' || coalesce(updated_by, '') || ' ' || coalesce(deleted_by, ''),
+        '[@._+-]+', ' ', 'g')), 'B'))`
+)
+
+// searchSimilarity is the pg_trgm word similarity from which a misspelled
+// query still matches, the default of 0.6 misses most single typos in short
+// queries.
+const searchSimilarity = "0.3"
+
 type repository struct {
 	db     *sqlx.DB
 	schema string
@@ -30,30 +131,39 @@ func NewRepository(db *sqlx.DB, schema string) Repository {
 	return &repository{db, schema}
 }
 
-func (r *repository) Insert(user *model.User) error {
-	queryPrep, err := r.db.Preparex(fmt.Sprintf(`INSERT INTO %s.users (email, password) VALUES ($1, $2)`, r.schema))
+func (r *repository) Insert(user *model.User, actor string) error {
+	if user.Status == "" {
+		user.Status = model.UserStatusActive
+	}
+
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`INSERT INTO %s.users (email, password, status, email_verified_at, created_by)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`, r.schema))
 	if err != nil {
 		return err
 	}
 
-	_, err = queryPrep.Exec(user.Email, user.Password)
-
+	err = queryPrep.QueryRowx(user.Email, user.Password, user.Status, user.EmailVerifiedAt, actor).Scan(&user.ID, &user.CreatedAt)
 	if err != nil {
 		return err
 	}
 
+	user.CreatedBy = &actor
+
 	return nil
 }
 
 func (r *repository) GetByEmail(email string) (user *model.User, err error) {
 	var res model.User
 
-	query, err := r.db.Preparex(fmt.Sprintf(`SELECT * from %s."users" where email = $1`, r.schema))
+	query, err := r.db.Preparex(fmt.Sprintf(`SELECT * from %s."users" where email = $1 and deleted_at is null`, r.schema))
 	if err != nil {
 		return nil, err
 	}
 
 	err = query.Get(&res, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -61,13 +171,32 @@ func (r *repository) GetByEmail(email string) (user *model.User, err error) {
 	return &res, nil
 }
 
-func (r *repository) GetAll() (users []model.User, err error) {
-	query, err := r.db.Preparex(fmt.Sprintf(`select * from %s."users" where deleted_at is null`, r.schema))
+func (r *repository) List(filter UserFilter, page UserPage) (users []model.User, err error) {
+	if !slices.Contains(UserSortFields, page.SortBy) {
+		return nil, fmt.Errorf("unsupported sort field %q", page.SortBy)
+	}
+
+	conditions, args := filter.conditions()
+
+	direction, comparison := "asc", ">"
+	if page.Desc {
+		direction, comparison = "desc", "<"
+	}
+
+	if page.After != nil {
+		args = append(args, page.After.Value, page.After.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d, $%d)", page.SortBy, comparison, len(args)-1, len(args)))
+	}
+
+	args = append(args, page.Limit, page.Offset)
+
+	query, err := r.db.Preparex(fmt.Sprintf(`select * from %s."users" %s order by %s %s, id %s limit $%d offset $%d`,
+		r.schema, where(conditions), page.SortBy, direction, direction, len(args)-1, len(args)))
 	if err != nil {
 		return nil, err
 	}
 
-	err = query.Select(&users)
+	err = query.Select(&users, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -75,30 +204,122 @@ func (r *repository) GetAll() (users []model.User, err error) {
 	return users, nil
 }
 
-func (r *repository) Update(user *model.User) error {
-	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.users set email=$1, password=$2,updated_at=$3 WHERE id = $4`, r.schema))
+func (r *repository) Count(filter UserFilter) (total int64, err error) {
+	conditions, args := filter.conditions()
+
+	query, err := r.db.Preparex(fmt.Sprintf(`select count(*) from %s."users" %s`, r.schema, where(conditions)))
+	if err != nil {
+		return 0, err
+	}
+
+	err = query.Get(&total, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (f UserFilter) conditions() (conditions []string, args []interface{}) {
+	if !f.IncludeDeleted {
+		conditions = append(conditions, "deleted_at is null")
+	}
+
+	if f.EmailContains != "" {
+		args = append(args, "%"+escapeLike(f.EmailContains)+"%")
+		conditions = append(conditions, fmt.Sprintf("email ilike $%d", len(args)))
+	}
+
+	if f.CreatedFrom != nil {
+		args = append(args, *f.CreatedFrom)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+
+	if f.CreatedTo != nil {
+		args = append(args, *f.CreatedTo)
+		conditions = append(conditions, fmt.Sprintf("created_at <= $%d", len(args)))
+	}
+
+	return conditions, args
+}
+
+func where(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+
+	return "where " + strings.Join(conditions, " and ")
+}
+
+// escapeLike makes the wildcards of a like pattern match literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func (r *repository) Update(user *model.User, actor string) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.users set email=$1, password=$2,updated_at=$3, updated_by=$4,
+		email_verified_at = CASE WHEN email = $1 THEN email_verified_at END WHERE id = $5 and deleted_at is null`, r.schema))
 	if err != nil {
 		return err
 	}
 
-	_, err = queryPrep.Exec(user.Email, user.Password, time.Now(), user.ID)
+	now := time.Now()
+
+	_, err = queryPrep.Exec(user.Email, user.Password, now, actor, user.ID)
 
 	if err != nil {
 		return err
 	}
 
+	user.UpdatedAt = &now
+	user.UpdatedBy = &actor
+
 	return nil
 }
 
+func (r *repository) UpdatePassword(id int64, password string, actor string) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.users set password = $1, updated_at = $2, updated_by = $3
+		where id = $4 and deleted_at is null`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(password, time.Now(), actor, id)
+
+	return err
+}
+
 func (r *repository) GetByID(id int64) (user *model.User, err error) {
 	var res model.User
 
+	query, err := r.db.Preparex(fmt.Sprintf(`SELECT * from %s."users" where id = $1 and deleted_at is null`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Get(&res, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *repository) GetByIDWithDeleted(id int64) (user *model.User, err error) {
+	var res model.User
+
 	query, err := r.db.Preparex(fmt.Sprintf(`SELECT * from %s."users" where id = $1`, r.schema))
 	if err != nil {
 		return nil, err
 	}
 
 	err = query.Get(&res, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -106,17 +327,246 @@ func (r *repository) GetByID(id int64) (user *model.User, err error) {
 	return &res, nil
 }
 
-func (r *repository) SoftDelete(id int64) error {
-	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.users set deleted_at = $1 where id = $2`, r.schema))
+func (r *repository) GetStatus(id int64) (status string, err error) {
+	query, err := r.db.Preparex(fmt.Sprintf(`SELECT status from %s."users" where id = $1`, r.schema))
+	if err != nil {
+		return "", err
+	}
+	defer query.Close()
+
+	err = query.Get(&status, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+
+	return status, err
+}
+
+func (r *repository) MarkEmailVerified(id int64, email string) (bool, error) {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.users set email_verified_at = COALESCE(email_verified_at, $1)
+		where id = $2 and email = $3 and deleted_at is null`, r.schema))
+	if err != nil {
+		return false, err
+	}
+
+	res, err := queryPrep.Exec(time.Now(), id, email)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *repository) GetPasswordHistory(userID int64, limit int) (passwords []string, err error) {
+	query, err := r.db.Preparex(fmt.Sprintf(`select password from %s.password_history where user_id = $1
+		order by created_at desc, id desc limit $2`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Select(&passwords, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return passwords, nil
+}
+
+func (r *repository) AddPasswordHistory(userID int64, password string, keep int) error {
+	tx, err := r.db.Beginx()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	_, err = queryPrep.Exec(time.Now(), id)
+	_, err = tx.Exec(fmt.Sprintf(`insert into %s.password_history (user_id, password) values ($1, $2)`, r.schema),
+		userID, password)
+	if err != nil {
+		return err
+	}
 
+	_, err = tx.Exec(fmt.Sprintf(`delete from %[1]s.password_history where user_id = $1 and id not in (
+		select id from %[1]s.password_history where user_id = $1 order by created_at desc, id desc limit $2)`, r.schema),
+		userID, keep)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return tx.Commit()
+}
+
+func (r *repository) Search(search UserSearch) (matches []UserMatch, total int64, err error) {
+	terms := make([]string, len(search.Terms))
+	for i, term := range search.Terms {
+		terms[i] = term + ":*"
+	}
+
+	// $1 query, $2 tsquery, $3 like pattern
+	args := []interface{}{search.Query, strings.Join(terms, " & "), "%" + escapeLike(search.Query) + "%"}
+
+	conditions := []string{fmt.Sprintf(`(email ilike $3 or $1 <%% email or %[1]s ilike $3 or $1 <%% %[1]s
+		or %[2]s @@ to_tsquery('simple', $2))`, userAuditColumns, userSearchDocument)}
+	if !search.IncludeDeleted {
+		conditions = append(conditions, "deleted_at is null")
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	// only for this transaction
+	_, err = tx.Exec(`select set_config('pg_trgm.word_similarity_threshold', $1, true)`, searchSimilarity)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = tx.Get(&total, fmt.Sprintf(`select count(*) from %s."users" %s`, r.schema, where(conditions)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, search.Limit, search.Offset)
+
+	// email matches count most, then whole words and finally the audit columns
+	err = tx.Select(&matches, fmt.Sprintf(`select *, (
+			2 * word_similarity($1, email)
+			+ ts_rank(%[2]s, to_tsquery('simple', $2))
+			+ 0.5 * word_similarity($1, %[3]s)
+		) as score
+		from %[1]s."users" %[4]s
+		order by score desc, id asc limit $4 offset $5`, r.schema, userSearchDocument, userAuditColumns, where(conditions)),
+		args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return matches, total, tx.Commit()
+}
+
+func (r *repository) UpdateStatus(change *model.UserStatusChange) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	res, err := tx.Exec(fmt.Sprintf(`update %s.users set status = $1, updated_at = $2, updated_by = $3,
+		deleted_at = CASE WHEN $1 = 'deleted' THEN $2 END, deleted_by = CASE WHEN $1 = 'deleted' THEN $3 END
+		where id = $4 and status = $5 and anonymized_at is null`, r.schema),
+		change.ToStatus, now, change.Actor, change.UserID, change.FromStatus)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if affected != 1 {
+		return false, nil
+	}
+
+	err = tx.QueryRowx(fmt.Sprintf(`insert into %s.user_status_changes (user_id, from_status, to_status, reason, actor, created_at)
+		values ($1, $2, $3, $4, $5, $6) returning id`, r.schema),
+		change.UserID, change.FromStatus, change.ToStatus, change.Reason, change.Actor, now).Scan(&change.ID)
+	if err != nil {
+		return false, err
+	}
+
+	change.CreatedAt = now
+
+	return true, tx.Commit()
+}
+
+func (r *repository) GetStatusChanges(userID int64) (changes []model.UserStatusChange, err error) {
+	query, err := r.db.Preparex(fmt.Sprintf(`select * from %s.user_status_changes where user_id = $1
+		order by created_at, id`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Select(&changes, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func (r *repository) Purge(id int64) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`delete from %s.users where id = $1`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(id)
+
+	return err
+}
+
+func (r *repository) PurgeDeleted(before time.Time) (int64, error) {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`delete from %s.users where deleted_at < $1`, r.schema))
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := queryPrep.Exec(before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// userCredentialTables hold everything of a user that AnonymizeDeleted erases.
+var userCredentialTables = []string{
+	"refresh_tokens", "sessions", "password_reset_tokens", "password_history", "user_totp", "recovery_codes",
+	"webauthn_credentials", "webauthn_sessions", "oauth_authorization_codes", "oauth_consents", "user_roles",
+}
+
+func (r *repository) AnonymizeDeleted(before time.Time) (int64, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var ids []int64
+	err = tx.Select(&ids, fmt.Sprintf(`select id from %s.users where deleted_at < $1 and anonymized_at is null
+		for update`, r.schema), before)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	for _, table := range userCredentialTables {
+		_, err = tx.Exec(fmt.Sprintf(`delete from %s.%s where user_id = any($1)`, r.schema, table), pq.Array(ids))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// the placeholder keeps the column not null and is no deliverable address,
+	// the emptied password matches no hash format
+	_, err = tx.Exec(fmt.Sprintf(`update %s.users set email = 'deleted-' || id, password = '',
+		email_verified_at = null, anonymized_at = $1, updated_at = $1, updated_by = $2 where id = any($3)`, r.schema),
+		time.Now(), principal.ActorSystem, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(ids)), tx.Commit()
 }
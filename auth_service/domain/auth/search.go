@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// MinSearchLength keeps single characters, which match nearly everyone, out
+// of the search.
+const MinSearchLength = 2
+
+// searchTerms splits a query into the words the full text index holds, email
+// addresses are indexed split at their punctuation as well.
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// highlight wraps the parts of value that matched in <mark> tags and escapes
+// the rest for HTML. A literal occurrence of query is marked as a whole,
+// otherwise each word close to one of terms, which covers typos. It returns
+// "" when nothing matched.
+func highlight(value string, query string, terms []string) string {
+	lower, lowerQuery := strings.ToLower(value), strings.ToLower(query)
+
+	// lower casing keeps byte offsets for everything but a few exotic runes
+	if i := strings.Index(lower, lowerQuery); query != "" && i >= 0 && len(lower) == len(value) {
+		end := i + len(lowerQuery)
+		return html.EscapeString(value[:i]) + "<mark>" + html.EscapeString(value[i:end]) + "</mark>" +
+			html.EscapeString(value[end:])
+	}
+
+	var out strings.Builder
+	matched := false
+	start := -1
+
+	flush := func(end int) {
+		word := value[start:end]
+		if matchesTerm(strings.ToLower(word), terms) {
+			matched = true
+			out.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+		} else {
+			out.WriteString(html.EscapeString(word))
+		}
+		start = -1
+	}
+
+	for i, r := range value {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			flush(i)
+		}
+
+		if !isWord {
+			out.WriteString(html.EscapeString(string(r)))
+		}
+	}
+
+	if start >= 0 {
+		flush(len(value))
+	}
+
+	if !matched {
+		return ""
+	}
+
+	return out.String()
+}
+
+// matchesTerm reports whether word starts with one of terms or with a string
+// at most one edit per four characters away from it.
+func matchesTerm(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+
+		prefix := word
+		if len([]rune(prefix)) > len([]rune(term)) {
+			prefix = string([]rune(prefix)[:len([]rune(term))])
+		}
+
+		if len([]rune(term)) >= 4 && editDistance(prefix, term) <= len([]rune(term))/4 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// editDistance counts insertions, deletions, substitutions and swaps of
+// adjacent characters, the usual typos.
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/helyus1412/auth-service/model"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+)
+
+var (
+	ErrInvalidTransition = errors.New("status transition is not allowed")
+	// ErrStatusChanged means the user's status changed since it was read.
+	ErrStatusChanged = errors.New("user status changed concurrently")
+)
+
+// statusTransitions lists the statuses each status may change to. Deleted
+// users only come back through a restore, which returns them to the status
+// they had when they were deleted, see StatusBeforeDeletion.
+var statusTransitions = map[string][]string{
+	model.UserStatusPending:   {model.UserStatusActive, model.UserStatusSuspended, model.UserStatusDeleted},
+	model.UserStatusActive:    {model.UserStatusSuspended, model.UserStatusLocked, model.UserStatusDeleted},
+	model.UserStatusSuspended: {model.UserStatusActive, model.UserStatusDeleted},
+	model.UserStatusLocked:    {model.UserStatusActive, model.UserStatusSuspended, model.UserStatusDeleted},
+	model.UserStatusDeleted:   {model.UserStatusPending, model.UserStatusActive, model.UserStatusSuspended, model.UserStatusLocked},
+}
+
+// CanTransition reports whether a user may move from one status to another.
+func CanTransition(from string, to string) bool {
+	return slices.Contains(statusTransitions[from], to)
+}
+
+// Transition moves user to status to, recording reason and actor with the
+// change. It returns ErrInvalidTransition for a change CanTransition forbids
+// and ErrStatusChanged when the stored status no longer matches user.
+func Transition(repository Repository, user *model.User, to string, reason string, actor string) error {
+	if !CanTransition(user.Status, to) {
+		return ErrInvalidTransition
+	}
+
+	changed, err := repository.UpdateStatus(&model.UserStatusChange{
+		UserID:     user.ID,
+		FromStatus: user.Status,
+		ToStatus:   to,
+		Reason:     reason,
+		Actor:      actor,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !changed {
+		return ErrStatusChanged
+	}
+
+	user.Status = to
+	user.UpdatedBy = &actor
+
+	return nil
+}
+
+// StatusBeforeDeletion returns the status the user had when last deleted,
+// taken from the status history. Users deleted before the history was kept
+// count as active.
+func StatusBeforeDeletion(repository Repository, userID int64) (string, error) {
+	changes, err := repository.GetStatusChanges(userID)
+	if err != nil {
+		return "", err
+	}
+
+	for i := len(changes) - 1; i >= 0; i-- {
+		if changes[i].ToStatus == model.UserStatusDeleted {
+			return changes[i].FromStatus, nil
+		}
+	}
+
+	return model.UserStatusActive, nil
+}
+
+// TransitionError maps a failed Transition to a response error.
+func TransitionError(err error) interface{} {
+	switch {
+	case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrStatusChanged):
+		return httpError.NewConflict(err.Error())
+	default:
+		return httpError.NewInternalServerError(err.Error())
+	}
+}
+
+// StatusError returns the response error refusing a sign-in of user because
+// of its status, or nil for active users. Each status has its own code so
+// clients can tell the user what to do.
+func StatusError(user *model.User) interface{} {
+	switch user.Status {
+	case model.UserStatusActive:
+		return nil
+	case model.UserStatusPending:
+		return httpError.NewCustomError(http.StatusForbidden, "ACCOUNT-PENDING",
+			"account is not activated yet, follow the link we sent to your email address")
+	case model.UserStatusSuspended:
+		return httpError.NewCustomError(http.StatusForbidden, "ACCOUNT-SUSPENDED",
+			"account is suspended, contact support")
+	case model.UserStatusLocked:
+		return httpError.NewCustomError(http.StatusLocked, "ACCOUNT-RESET-REQUIRED",
+			"account is locked, reset your password to unlock it")
+	default:
+		return httpError.NewCustomError(http.StatusForbidden, "ACCOUNT-DELETED", "account is deleted")
+	}
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/helyus1412/auth-service/model"
+)
+
+func TestCanTransition(t *testing.T) {
+	const (
+		pending   = model.UserStatusPending
+		active    = model.UserStatusActive
+		suspended = model.UserStatusSuspended
+		locked    = model.UserStatusLocked
+		deleted   = model.UserStatusDeleted
+	)
+
+	tests := []struct {
+		from string
+		to   string
+		want bool
+	}{
+		{pending, pending, false},
+		{pending, active, true},
+		{pending, suspended, true},
+		{pending, locked, false},
+		{pending, deleted, true},
+
+		{active, pending, false},
+		{active, active, false},
+		{active, suspended, true},
+		{active, locked, true},
+		{active, deleted, true},
+
+		{suspended, pending, false},
+		{suspended, active, true},
+		{suspended, suspended, false},
+		{suspended, locked, false},
+		{suspended, deleted, true},
+
+		{locked, pending, false},
+		{locked, active, true},
+		{locked, suspended, true},
+		{locked, locked, false},
+		{locked, deleted, true},
+
+		// a restore returns the user to their status before deletion
+		{deleted, pending, true},
+		{deleted, active, true},
+		{deleted, suspended, true},
+		{deleted, locked, true},
+		{deleted, deleted, false},
+
+		{"", active, false},
+		{active, "", false},
+		{"banned", active, false},
+		{active, "banned", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			if got := CanTransition(tt.from, tt.to); got != tt.want {
+				t.Fatalf("CanTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,88 +2,957 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/helyus1412/auth-service/domain/mfa"
+	"github.com/helyus1412/auth-service/domain/passkey"
+	"github.com/helyus1412/auth-service/domain/rbac"
+	"github.com/helyus1412/auth-service/domain/refresh"
+	"github.com/helyus1412/auth-service/domain/session"
+	"github.com/helyus1412/auth-service/domain/throttle"
 	"github.com/helyus1412/auth-service/dto"
 	"github.com/helyus1412/auth-service/model"
+	"github.com/helyus1412/auth-service/pkg/hasher"
 	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"github.com/helyus1412/auth-service/pkg/mailer"
+	"github.com/helyus1412/auth-service/pkg/passwordpolicy"
+	"github.com/helyus1412/auth-service/pkg/principal"
+	"github.com/helyus1412/auth-service/pkg/token"
 	"github.com/helyus1412/auth-service/pkg/utils"
+	"go.uber.org/zap"
 )
 
 type Usecase interface {
 	Register(context.Context, *dto.RegisterRequest) utils.Result
 	Login(context.Context, *dto.LoginRequest) utils.Result
-	ListUser(context.Context) utils.Result
+	Authenticate(context.Context, *dto.LoginRequest) utils.Result
+	LoginMFA(context.Context, *dto.LoginMFARequest) utils.Result
+	AuthenticateMFA(context.Context, *dto.LoginMFARequest) utils.Result
+	LoginMFAPasskey(context.Context, *dto.LoginMFAPasskeyRequest) utils.Result
+	BeginPasskeyLogin(context.Context) utils.Result
+	LoginPasskey(context.Context, *dto.PasskeyFinishRequest) utils.Result
+	Refresh(context.Context, *dto.RefreshTokenRequest) utils.Result
+	VerifyEmail(context.Context, *dto.VerifyEmailRequest) utils.Result
+	ResendVerification(context.Context, *dto.ResendVerificationRequest) utils.Result
+	ListUser(context.Context, *dto.ListUsersRequest) utils.Result
+	SearchUsers(context.Context, *dto.SearchUsersRequest) utils.Result
+	GetUser(context.Context, int64) utils.Result
 	Edit(context.Context, *dto.EditRequest) utils.Result
 	Delete(context.Context, int64) utils.Result
+	Restore(context.Context, int64) utils.Result
+	Purge(context.Context, int64) utils.Result
+	Suspend(context.Context, *dto.UserStatusRequest) utils.Result
+	Lock(context.Context, *dto.UserStatusRequest) utils.Result
+	Reactivate(context.Context, *dto.UserStatusRequest) utils.Result
+	StatusHistory(context.Context, int64) utils.Result
+}
+
+// Authentication is a completed sign-in: the user and the methods used, as
+// reported in the amr claim.
+type Authentication struct {
+	User *model.User
+	AMR  []string
+}
+
+// DefaultRole is granted to every newly registered user.
+const DefaultRole = "user"
+
+// Authentication method references (RFC 8176) recorded in the amr claim.
+const (
+	AMRPassword = "pwd"
+	AMROTP      = "otp"
+	AMRHardware = "hwk"
+	AMRMFA      = "mfa"
+)
+
+const (
+	mfaTokenPurpose          = "mfa"
+	verificationTokenPurpose = "email_verification"
+)
+
+type Config struct {
+	MFATokenTTL time.Duration
+	// VerificationURL is the GET /verify-email endpoint the emailed link points at.
+	VerificationURL string
+	VerificationTTL time.Duration
+	// RequireVerifiedEmail refuses logins until the address is verified.
+	RequireVerifiedEmail bool
 }
 
 type usecase struct {
-	repository Repository
+	repository      Repository
+	rbacRepository  rbac.Repository
+	tokenManager    *token.Manager
+	refreshUsecase  refresh.Usecase
+	sessionUsecase  session.Usecase
+	mfaUsecase      mfa.Usecase
+	passkeyUsecase  passkey.Usecase
+	throttleUsecase throttle.Usecase
+	passwordHasher  hasher.PasswordHasher
+	passwordPolicy  *passwordpolicy.Policy
+	mailer          mailer.Mailer
+	config          Config
+	logger          *logger.Logger
+}
+
+func NewUsecase(repository Repository, rbacRepository rbac.Repository, tokenManager *token.Manager,
+	refreshUsecase refresh.Usecase, sessionUsecase session.Usecase, mfaUsecase mfa.Usecase, passkeyUsecase passkey.Usecase,
+	throttleUsecase throttle.Usecase, passwordHasher hasher.PasswordHasher, passwordPolicy *passwordpolicy.Policy,
+	mailer mailer.Mailer, config Config, logger *logger.Logger) Usecase {
+	return &usecase{repository, rbacRepository, tokenManager, refreshUsecase, sessionUsecase, mfaUsecase, passkeyUsecase, throttleUsecase,
+		passwordHasher, passwordPolicy, mailer, config, logger}
+}
+
+func (u *usecase) Register(ctx context.Context, payload *dto.RegisterRequest) (result utils.Result) {
+	existing, err := u.repository.GetByEmail(payload.Email)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if existing != nil {
+		result.Error = httpError.NewConflict("email is already registered")
+		return result
+	}
+
+	newUser := &model.User{Email: payload.Email}
+	if policyErr := CheckPassword(ctx, u.repository, u.passwordPolicy, u.logger, newUser, payload.Password); policyErr != nil {
+		result.Error = policyErr
+		return result
+	}
+
+	hashedPassword, err := u.passwordHasher.Hash(payload.Password)
+	if err != nil {
+		result.Error = HashError(err)
+		return result
+	}
+
+	user := &model.User{
+		Email:    payload.Email,
+		Password: hashedPassword,
+		Status:   model.UserStatusActive,
+	}
+
+	// the account becomes active once the address is verified
+	if u.config.RequireVerifiedEmail {
+		user.Status = model.UserStatusPending
+	}
+
+	err = u.repository.Insert(user, principal.ActorFrom(ctx, principal.ActorSelf))
+
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	role, err := u.rbacRepository.GetRoleByName(DefaultRole)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if role != nil {
+		err = u.rbacRepository.AssignRole(user.ID, role.ID)
+		if err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+	}
+
+	u.sendVerification(ctx, user)
+
+	return result
+}
+
+func (u *usecase) Login(ctx context.Context, payload *dto.LoginRequest) (result utils.Result) {
+	result = u.Authenticate(ctx, payload)
+	if result.Error != nil {
+		return result
+	}
+
+	// otherwise Data is the challenge for the second factor
+	user, ok := result.Data.(*model.User)
+	if !ok {
+		return result
+	}
+
+	response, err := u.issueTokens(ctx, user, []string{AMRPassword}, payload.IP, payload.UserAgent)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	result.Data = response
+
+	return result
+}
+
+// Authenticate checks a password sign-in: it applies the login throttle, the
+// account status and the verified email requirement. Data is the
+// *model.User, or a *dto.MFAChallengeResponse when the user has to complete a
+// second factor first.
+func (u *usecase) Authenticate(ctx context.Context, payload *dto.LoginRequest) (result utils.Result) {
+	block, err := u.throttleUsecase.Check(ctx, 0, payload.IP)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if block != nil {
+		result.Error = blockedError(block)
+		return result
+	}
+
+	// hash password
+	user, err := u.repository.GetByEmail(payload.Email)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if user == nil {
+		if err := u.throttleUsecase.RecordFailure(ctx, 0, payload.IP); err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+
+		result.Error = httpError.NewBadRequest("user not found")
+		return result
+	}
+
+	block, err = u.throttleUsecase.Check(ctx, user.ID, "")
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if block != nil {
+		result.Error = blockedError(block)
+		return result
+	}
+
+	if !VerifyPassword(ctx, u.repository, u.passwordHasher, u.logger, user, payload.Password) {
+		if err := u.throttleUsecase.RecordFailure(ctx, user.ID, payload.IP); err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+
+		result.Error = httpError.NewBadRequest("invalid password")
+		return result
+	}
+
+	if err := u.loginPolicy(user); err != nil {
+		result.Error = err
+		return result
+	}
+
+	methods, err := u.secondFactors(ctx, user.ID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	// password alone is not enough, hand out a challenge for the second step
+	if len(methods) > 0 {
+		mfaToken, err := u.tokenManager.IssuePurposeToken(token.UserSubject(user.ID), mfaTokenPurpose, u.config.MFATokenTTL)
+		if err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+
+		result.Data = &dto.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			Methods:     methods,
+		}
+		return result
+	}
+
+	// the counter is only cleared once the whole login succeeded, otherwise a
+	// known password would reset it between guesses at the second factor
+	if err := u.throttleUsecase.RecordSuccess(ctx, user.ID); err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	result.Data = user
+
+	return result
+}
+
+func (u *usecase) LoginMFA(ctx context.Context, payload *dto.LoginMFARequest) (result utils.Result) {
+	result = u.AuthenticateMFA(ctx, payload)
+	if result.Error != nil {
+		return result
+	}
+
+	authentication := result.Data.(*Authentication)
+
+	response, err := u.issueTokens(ctx, authentication.User, authentication.AMR, payload.IP, payload.UserAgent)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	result.Data = response
+
+	return result
+}
+
+// AuthenticateMFA completes the second factor of an Authenticate challenge.
+// Data is the *Authentication.
+func (u *usecase) AuthenticateMFA(ctx context.Context, payload *dto.LoginMFARequest) (result utils.Result) {
+	userID, err := u.parseMFAToken(payload.MFAToken)
+	if err != nil {
+		result.Error = httpError.NewUnauthorized(err.Error())
+		return result
+	}
+
+	block, err := u.throttleUsecase.Check(ctx, userID, payload.IP)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if block != nil {
+		result.Error = blockedError(block)
+		return result
+	}
+
+	amr := []string{AMRPassword, AMROTP, AMRMFA}
+
+	var valid bool
+
+	switch {
+	case len(payload.Credential) > 0:
+		amr = []string{AMRPassword, AMRHardware, AMRMFA}
+
+		_, err = u.passkeyUsecase.FinishLogin(ctx, payload.SessionID, payload.Credential, userID)
+		if errors.Is(err, passkey.ErrInvalidSession) || errors.Is(err, passkey.ErrInvalidAssertion) {
+			valid, err = false, nil
+		} else {
+			valid = err == nil
+		}
+	case payload.Code != "" || payload.RecoveryCode != "":
+		valid, err = u.mfaUsecase.Verify(ctx, userID, payload.Code, payload.RecoveryCode)
+	default:
+		result.Error = httpError.NewBadRequest("code, recovery_code or credential is required")
+		return result
+	}
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if !valid {
+		if err := u.throttleUsecase.RecordFailure(ctx, userID, payload.IP); err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+
+		result.Error = httpError.NewUnauthorized("invalid code")
+		return result
+	}
+
+	if err := u.throttleUsecase.RecordSuccess(ctx, userID); err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	user, err := u.repository.GetByID(userID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if user == nil || user.DeletedAt != nil {
+		result.Error = httpError.NewUnauthorized("user not found")
+		return result
+	}
+
+	// the status may have changed since the first step
+	if err := u.loginPolicy(user); err != nil {
+		result.Error = err
+		return result
+	}
+
+	result.Data = &Authentication{User: user, AMR: amr}
+
+	return result
+}
+
+// LoginMFAPasskey starts a passkey assertion as the second login step.
+func (u *usecase) LoginMFAPasskey(ctx context.Context, payload *dto.LoginMFAPasskeyRequest) (result utils.Result) {
+	userID, err := u.parseMFAToken(payload.MFAToken)
+	if err != nil {
+		result.Error = httpError.NewUnauthorized(err.Error())
+		return result
+	}
+
+	options, err := u.passkeyUsecase.BeginLogin(ctx, userID)
+	if errors.Is(err, passkey.ErrInvalidSession) {
+		result.Error = httpError.NewBadRequest("no passkey registered")
+		return result
+	}
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	result.Data = options
+
+	return result
+}
+
+// BeginPasskeyLogin starts a passwordless login with a discoverable credential.
+func (u *usecase) BeginPasskeyLogin(ctx context.Context) (result utils.Result) {
+	options, err := u.passkeyUsecase.BeginLogin(ctx, 0)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	result.Data = options
+
+	return result
+}
+
+func (u *usecase) LoginPasskey(ctx context.Context, payload *dto.PasskeyFinishRequest) (result utils.Result) {
+	assertion, err := u.passkeyUsecase.FinishLogin(ctx, payload.SessionID, payload.Credential, 0)
+	if errors.Is(err, passkey.ErrInvalidSession) || errors.Is(err, passkey.ErrInvalidAssertion) {
+		result.Error = httpError.NewUnauthorized(err.Error())
+		return result
+	}
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	user, err := u.repository.GetByID(assertion.UserID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if user == nil || user.DeletedAt != nil {
+		result.Error = httpError.NewUnauthorized("user not found")
+		return result
+	}
+
+	if err := u.loginPolicy(user); err != nil {
+		result.Error = err
+		return result
+	}
+
+	// a device unlocked with a pin or biometric proves possession and
+	// verification together, which counts as two factors
+	amr := []string{AMRHardware}
+	if assertion.UserVerified {
+		amr = append(amr, AMRMFA)
+	}
+
+	response, err := u.issueTokens(ctx, user, amr, payload.IP, payload.UserAgent)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	result.Data = response
+
+	return result
+}
+
+// secondFactors lists the methods the user can complete the login with after
+// the password. It is empty when the user has none configured.
+func (u *usecase) secondFactors(ctx context.Context, userID int64) ([]string, error) {
+	methods := []string{}
+
+	totpEnabled, err := u.mfaUsecase.IsEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if totpEnabled {
+		methods = append(methods, "totp", "recovery_code")
+	}
+
+	hasPasskey, err := u.passkeyUsecase.HasCredentials(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasPasskey {
+		methods = append(methods, "webauthn")
+	}
+
+	return methods, nil
+}
+
+func (u *usecase) parseMFAToken(raw string) (int64, error) {
+	claims, err := u.tokenManager.ParsePurposeToken(raw, mfaTokenPurpose)
+	if err != nil {
+		return 0, errors.New("invalid or expired mfa token")
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid or expired mfa token")
+	}
+
+	return userID, nil
+}
+
+func (u *usecase) Refresh(ctx context.Context, payload *dto.RefreshTokenRequest) (result utils.Result) {
+	if payload.RefreshToken == "" {
+		result.Error = httpError.NewBadRequest("refresh token is required")
+		return result
+	}
+
+	previous, refreshToken, err := u.refreshUsecase.Rotate(ctx, payload.RefreshToken, "")
+	if errors.Is(err, refresh.ErrInvalidToken) || errors.Is(err, refresh.ErrExpiredToken) || errors.Is(err, refresh.ErrTokenReused) {
+		result.Error = httpError.NewUnauthorized(err.Error())
+		return result
+	}
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	// tokens issued before sessions were recorded carry none
+	sessionID := ""
+	if previous.SessionID != nil {
+		sessionID = *previous.SessionID
+
+		err = u.sessionUsecase.Touch(ctx, sessionID, payload.IP)
+		if errors.Is(err, session.ErrRevoked) {
+			result.Error = httpError.NewUnauthorized(err.Error())
+			return result
+		}
+		if err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+	}
+
+	user, err := u.repository.GetByID(previous.UserID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if user == nil || user.DeletedAt != nil {
+		result.Error = httpError.NewUnauthorized("user not found")
+		return result
+	}
+
+	if err := StatusError(user); err != nil {
+		result.Error = err
+		return result
+	}
+
+	response, err := u.tokenResponse(user, refreshToken, previous.AMR, sessionID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	result.Data = response
+
+	return result
+}
+
+func (u *usecase) VerifyEmail(ctx context.Context, payload *dto.VerifyEmailRequest) (result utils.Result) {
+	claims, err := u.tokenManager.ParsePurposeToken(payload.Token, verificationTokenPurpose)
+	if err != nil {
+		result.Error = httpError.NewBadRequest("invalid or expired verification link")
+		return result
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		result.Error = httpError.NewBadRequest("invalid or expired verification link")
+		return result
+	}
+
+	// the link only proves ownership of the address it was sent to
+	verified, err := u.repository.MarkEmailVerified(userID, claims.Email)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if !verified {
+		result.Error = httpError.NewBadRequest("invalid or expired verification link")
+		return result
+	}
+
+	user, err := u.repository.GetByID(userID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if user != nil && user.Status == model.UserStatusPending {
+		err = Transition(u.repository, user, model.UserStatusActive, "email address verified", principal.ActorSelf)
+		if err != nil && !errors.Is(err, ErrStatusChanged) {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+	}
+
+	return result
+}
+
+// ResendVerification always succeeds so that the response does not reveal
+// whether the address belongs to an account.
+func (u *usecase) ResendVerification(ctx context.Context, payload *dto.ResendVerificationRequest) (result utils.Result) {
+	user, err := u.repository.GetByEmail(payload.Email)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if user == nil || user.DeletedAt != nil || user.EmailVerifiedAt != nil {
+		return result
+	}
+
+	u.sendVerification(ctx, user)
+
+	return result
+}
+
+// blockedError maps a throttling decision onto the response error.
+func blockedError(block *throttle.Block) interface{} {
+	data := &dto.LoginBlockedResponse{
+		RetryAfter: int64(math.Ceil(time.Until(block.Until).Seconds())),
+	}
+
+	if block.Locked {
+		data.LockedUntil = &block.Until
+		return httpError.NewCustomError(http.StatusLocked, "ACCOUNT-LOCKED",
+			"account is locked after too many failed login attempts", data)
+	}
+
+	return httpError.NewCustomError(http.StatusTooManyRequests, "LOGIN-THROTTLED",
+		"too many failed login attempts, try again later", data)
+}
+
+// loginPolicy decides whether an authenticated user may be issued tokens. It
+// returns the httpError to respond with, or nil.
+func (u *usecase) loginPolicy(user *model.User) interface{} {
+	if err := StatusError(user); err != nil {
+		return err
+	}
+
+	if u.config.RequireVerifiedEmail && user.EmailVerifiedAt == nil {
+		return httpError.NewCustomError(http.StatusForbidden, "EMAIL-NOT-VERIFIED",
+			"email address is not verified, follow the link we sent or request a new one")
+	}
+
+	return nil
+}
+
+// sendVerification emails a signed link to the user's current address. Delivery
+// runs in the background and failures are only logged, the user can ask for
+// a new link.
+func (u *usecase) sendVerification(ctx context.Context, user *model.User) {
+	claims := token.Claims{Email: user.Email}
+	claims.Subject = token.UserSubject(user.ID)
+
+	raw, err := u.tokenManager.IssuePurposeClaims(claims, verificationTokenPurpose, u.config.VerificationTTL)
+	if err != nil {
+		u.logger.Error(ctx, "auth.sendVerification", "IssueToken", "failed to issue verification token", err,
+			zap.Int64("user_id", user.ID))
+		return
+	}
+
+	link := u.config.VerificationURL + "?token=" + url.QueryEscape(raw)
+	message := mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Please confirm that this address belongs to you by opening the link below within %s:\n%s\n\n"+
+			"If you did not create an account, you can ignore this email.\n", u.config.VerificationTTL, link),
+	}
+
+	go func(ctx context.Context) {
+		if err := u.mailer.Send(ctx, message); err != nil {
+			u.logger.Error(ctx, "auth.sendVerification", "SendMail", "failed to send verification email", err,
+				zap.Int64("user_id", user.ID))
+		}
+	}(context.WithoutCancel(ctx))
+}
+
+// issueTokens starts a session for the client with a new refresh token family
+// and returns it with a fresh access token.
+func (u *usecase) issueTokens(ctx context.Context, user *model.User, amr []string, ip string, userAgent string) (*dto.TokenResponse, error) {
+	userSession, err := u.sessionUsecase.Start(ctx, user.ID, ip, userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := u.refreshUsecase.Issue(ctx, user.ID, userSession.ID, amr)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.tokenResponse(user, refreshToken, amr, userSession.ID)
 }
 
-func NewUsecase(repository Repository) Usecase {
-	return &usecase{repository}
+func (u *usecase) tokenResponse(user *model.User, refreshToken string, amr []string, sessionID string) (*dto.TokenResponse, error) {
+	roles, err := u.rbacRepository.GetUserRoles(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := token.Claims{
+		Email:     user.Email,
+		Roles:     roles,
+		AMR:       amr,
+		SessionID: sessionID,
+	}
+	claims.Subject = token.UserSubject(user.ID)
+
+	accessToken, expiresAt, err := u.tokenManager.IssueAccessToken(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(time.Until(expiresAt).Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
 }
 
-func (u *usecase) Register(ctx context.Context, payload *dto.RegisterRequest) (result utils.Result) {
-	// hash password
+func (u *usecase) ListUser(ctx context.Context, payload *dto.ListUsersRequest) (result utils.Result) {
+	createdFrom, err := parseCreatedBound(payload.CreatedFrom, false)
+	if err != nil {
+		result.Error = httpError.NewBadRequest("created_from must be an RFC 3339 time or a date")
+		return result
+	}
 
-	hashedPassword, err := utils.HashPassword(payload.Password, 12)
+	createdTo, err := parseCreatedBound(payload.CreatedTo, true)
 	if err != nil {
-		result.Error = httpError.NewInternalServerError(err.Error())
+		result.Error = httpError.NewBadRequest("created_to must be an RFC 3339 time or a date")
 		return result
 	}
 
-	user := &model.User{
-		Email:    payload.Email,
-		Password: hashedPassword,
+	filter := UserFilter{
+		EmailContains:  payload.Email,
+		CreatedFrom:    createdFrom,
+		CreatedTo:      createdTo,
+		IncludeDeleted: payload.IncludeDeleted,
+	}
+
+	sort := payload.Sort
+	if sort == "" {
+		sort = "id"
+	}
+
+	page, ok := parseUserSort(sort)
+	if !ok {
+		result.Error = httpError.NewBadRequest("sort must be one of id, email or created_at, prefixed with - for descending order")
+		return result
+	}
+
+	limit := payload.Limit
+	if limit == 0 {
+		limit = DefaultPageSize
+	}
+
+	if limit < 1 || limit > MaxPageSize {
+		result.Error = httpError.NewBadRequest(fmt.Sprintf("limit must be between 1 and %d", MaxPageSize))
+		return result
+	}
+
+	pageNumber := payload.Page
+	if payload.Cursor != "" {
+		if pageNumber != 0 {
+			result.Error = httpError.NewBadRequest("page and cursor cannot be combined")
+			return result
+		}
+
+		page.After, err = decodeUserCursor(sort, payload.Cursor)
+		if err != nil {
+			result.Error = httpError.NewBadRequest("invalid cursor")
+			return result
+		}
+	} else {
+		if pageNumber == 0 {
+			pageNumber = 1
+		}
+
+		if pageNumber < 1 {
+			result.Error = httpError.NewBadRequest("page must be a positive number")
+			return result
+		}
+
+		page.Offset = (pageNumber - 1) * limit
 	}
 
-	err = u.repository.Insert(user)
+	// one extra row tells whether there is a next page
+	page.Limit = limit + 1
+
+	users, err := u.repository.List(filter, page)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
 
+	total, err := u.repository.Count(filter)
 	if err != nil {
 		result.Error = httpError.NewInternalServerError(err.Error())
 		return result
 	}
 
+	meta := utils.MetaData{
+		Page:      pageNumber,
+		TotalData: total,
+		TotalPage: math.Ceil(float64(total) / float64(limit)),
+	}
+
+	if len(users) > limit {
+		users = users[:limit]
+		meta.NextCursor = encodeUserCursor(sort, users[limit-1])
+	}
+
+	if users == nil {
+		users = []model.User{}
+	}
+
+	meta.Quantity = int64(len(users))
+
+	result.Data = users
+	result.MetaData = meta
+
 	return result
 }
 
-func (u *usecase) Login(ctx context.Context, payload *dto.LoginRequest) (result utils.Result) {
-	// hash password
-	user, err := u.repository.GetByEmail(payload.Email)
-	if err != nil {
-		result.Error = httpError.NewInternalServerError(err.Error())
+func (u *usecase) SearchUsers(ctx context.Context, payload *dto.SearchUsersRequest) (result utils.Result) {
+	query := strings.TrimSpace(payload.Query)
+	terms := searchTerms(query)
+
+	if len([]rune(query)) < MinSearchLength || len(terms) == 0 {
+		result.Error = httpError.NewBadRequest(fmt.Sprintf("q must be at least %d characters and contain a letter or digit",
+			MinSearchLength))
 		return result
 	}
 
-	if user == nil {
-		result.Error = httpError.NewBadRequest("user not found")
+	limit := payload.Limit
+	if limit == 0 {
+		limit = DefaultPageSize
+	}
+
+	if limit < 1 || limit > MaxPageSize {
+		result.Error = httpError.NewBadRequest(fmt.Sprintf("limit must be between 1 and %d", MaxPageSize))
 		return result
 	}
 
-	// check password
-	if err := utils.CheckPassword(user.Password, payload.Password); err != nil {
-		result.Error = httpError.NewBadRequest("invalid password")
+	page := payload.Page
+	if page == 0 {
+		page = 1
+	}
+
+	if page < 1 {
+		result.Error = httpError.NewBadRequest("page must be a positive number")
+		return result
+	}
+
+	matches, total, err := u.repository.Search(UserSearch{
+		Query:          query,
+		Terms:          terms,
+		IncludeDeleted: payload.IncludeDeleted,
+		Limit:          limit,
+		Offset:         (page - 1) * limit,
+	})
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
 		return result
 	}
 
+	results := make([]dto.UserSearchResult, 0, len(matches))
+	for _, match := range matches {
+		fields := map[string]*string{
+			"email":      &match.Email,
+			"created_by": match.CreatedBy,
+			"updated_by": match.UpdatedBy,
+			"deleted_by": match.DeletedBy,
+		}
+
+		highlights := map[string]string{}
+		for field, value := range fields {
+			if value == nil {
+				continue
+			}
+
+			if marked := highlight(*value, query, terms); marked != "" {
+				highlights[field] = marked
+			}
+		}
+
+		results = append(results, dto.UserSearchResult{User: match.User, Score: match.Score, Highlight: highlights})
+	}
+
+	result.Data = results
+	result.MetaData = utils.MetaData{
+		Page:      page,
+		Quantity:  int64(len(results)),
+		TotalPage: math.Ceil(float64(total) / float64(limit)),
+		TotalData: total,
+	}
+
 	return result
 }
 
-func (u *usecase) ListUser(ctx context.Context) (result utils.Result) {
-	users, err := u.repository.GetAll()
+// GetUser returns a single user, deleted ones included so their audit columns
+// stay visible.
+func (u *usecase) GetUser(ctx context.Context, id int64) (result utils.Result) {
+	user, err := u.repository.GetByIDWithDeleted(id)
 	if err != nil {
 		result.Error = httpError.NewInternalServerError(err.Error())
 		return result
 	}
 
-	result.Data = users
+	if user == nil {
+		result.Error = httpError.NewNotFound("user not found")
+		return result
+	}
+
+	result.Data = user
 
 	return result
 }
 
 func (u *usecase) Edit(ctx context.Context, payload *dto.EditRequest) (result utils.Result) {
+	// normal users may only edit themselves
+	actor, ok := principal.FromContext(ctx)
+	if !ok {
+		result.Error = httpError.NewUnauthorized("")
+		return result
+	}
+
+	if actor.UserID != payload.ID {
+		allowed, err := u.rbacRepository.HasPermission(actor.UserID, "users:update")
+		if err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+
+		if !allowed {
+			result.Error = httpError.NewForbidden("you may only edit your own account")
+			return result
+		}
+	}
+
 	// check if user exists
 	user, err := u.repository.GetByID(payload.ID)
 	if err != nil {
@@ -100,31 +969,60 @@ func (u *usecase) Edit(ctx context.Context, payload *dto.EditRequest) (result ut
 		ID:    payload.ID,
 		Email: payload.Email,
 	}
-	// hash password
-	if payload.Password != "" {
-		hashedPassword, err := utils.HashPassword(payload.Password, 12)
+	if payload.Email == "" {
+		userPayload.Email = user.Email
+	}
+
+	if userPayload.Email != user.Email {
+		existing, err := u.repository.GetByEmail(userPayload.Email)
 		if err != nil {
 			result.Error = httpError.NewInternalServerError(err.Error())
 			return result
 		}
 
-		userPayload.Password = hashedPassword
+		if existing != nil {
+			result.Error = httpError.NewConflict("email is already registered")
+			return result
+		}
 	}
 
-	if payload.Email == "" {
-		userPayload.Email = user.Email
+	// hash password
+	if payload.Password != "" {
+		// the email check uses the address the account is about to have
+		current := &model.User{ID: user.ID, Email: userPayload.Email, Password: user.Password}
+		if policyErr := CheckPassword(ctx, u.repository, u.passwordPolicy, u.logger, current, payload.Password); policyErr != nil {
+			result.Error = policyErr
+			return result
+		}
+
+		hashedPassword, err := u.passwordHasher.Hash(payload.Password)
+		if err != nil {
+			result.Error = HashError(err)
+			return result
+		}
+
+		userPayload.Password = hashedPassword
 	}
 
 	if payload.Password == "" {
 		userPayload.Password = user.Password
 	}
-	err = u.repository.Update(userPayload)
+	err = u.repository.Update(userPayload, principal.ActorFrom(ctx, principal.ActorSelf))
 
 	if err != nil {
 		result.Error = httpError.NewInternalServerError(err.Error())
 		return result
 	}
 
+	if payload.Password != "" {
+		RecordPassword(ctx, u.repository, u.passwordPolicy, u.logger, user.ID, user.Password)
+	}
+
+	// a new address has to be verified again
+	if userPayload.Email != user.Email {
+		u.sendVerification(ctx, userPayload)
+	}
+
 	return result
 }
 
@@ -141,11 +1039,195 @@ func (u *usecase) Delete(ctx context.Context, id int64) (result utils.Result) {
 		return result
 	}
 
-	err = u.repository.SoftDelete(id)
+	deletedBy := principal.ActorFrom(ctx, principal.ActorSystem)
+
+	err = Transition(u.repository, user, model.UserStatusDeleted, "", deletedBy)
+	if err != nil {
+		result.Error = TransitionError(err)
+		return result
+	}
+
+	// a deleted account must not stay signed in until its tokens expire
+	if err := u.sessionUsecase.RevokeUser(ctx, id); err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	return result
+}
+
+func (u *usecase) Restore(ctx context.Context, id int64) (result utils.Result) {
+	user, err := u.repository.GetByIDWithDeleted(id)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if user == nil {
+		result.Error = httpError.NewBadRequest("user not found")
+		return result
+	}
+
+	if user.Status != model.UserStatusDeleted {
+		result.Error = httpError.NewConflict("user is not deleted")
+		return result
+	}
+
+	if user.AnonymizedAt != nil {
+		result.Error = httpError.NewConflict("user was anonymized and can no longer be restored")
+		return result
+	}
+
+	// the address may have been registered again in the meantime
+	existing, err := u.repository.GetByEmail(user.Email)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if existing != nil {
+		result.Error = httpError.NewConflict("email is registered to another user")
+		return result
+	}
+
+	// restoring must not lift a suspension or lock the deletion came on top of
+	previous, err := StatusBeforeDeletion(u.repository, id)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	restoredBy := principal.ActorFrom(ctx, principal.ActorSystem)
+
+	err = Transition(u.repository, user, previous, "restored", restoredBy)
+	if err != nil {
+		result.Error = TransitionError(err)
+		return result
+	}
+
+	u.logger.Info(ctx, "auth.Restore", "Restore", "user restored", zap.Int64("user_id", id))
+
+	return result
+}
+
+func (u *usecase) Purge(ctx context.Context, id int64) (result utils.Result) {
+	actor, ok := principal.FromContext(ctx)
+	if !ok {
+		result.Error = httpError.NewUnauthorized("")
+		return result
+	}
+
+	if actor.UserID == id {
+		result.Error = httpError.NewForbidden("you may not purge your own account")
+		return result
+	}
+
+	user, err := u.repository.GetByIDWithDeleted(id)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if user == nil {
+		result.Error = httpError.NewBadRequest("user not found")
+		return result
+	}
+
+	err = u.repository.Purge(id)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	u.logger.Info(ctx, "auth.Purge", "Purge", "user purged", zap.Int64("user_id", id),
+		zap.String("purged_by", actor.Actor()))
+
+	return result
+}
+
+func (u *usecase) Suspend(ctx context.Context, payload *dto.UserStatusRequest) utils.Result {
+	return u.changeStatus(ctx, payload, model.UserStatusSuspended)
+}
+
+func (u *usecase) Lock(ctx context.Context, payload *dto.UserStatusRequest) utils.Result {
+	return u.changeStatus(ctx, payload, model.UserStatusLocked)
+}
+
+func (u *usecase) Reactivate(ctx context.Context, payload *dto.UserStatusRequest) utils.Result {
+	return u.changeStatus(ctx, payload, model.UserStatusActive)
+}
+
+// changeStatus moves another user to status on behalf of the caller and
+// signs the user out everywhere unless the account becomes active.
+func (u *usecase) changeStatus(ctx context.Context, payload *dto.UserStatusRequest, status string) (result utils.Result) {
+	actor, ok := principal.FromContext(ctx)
+	if !ok {
+		result.Error = httpError.NewUnauthorized("")
+		return result
+	}
+
+	if actor.UserID == payload.ID {
+		result.Error = httpError.NewForbidden("you may not change the status of your own account")
+		return result
+	}
+
+	reason := strings.TrimSpace(payload.Reason)
+	if reason == "" {
+		result.Error = httpError.NewBadRequest("reason is required")
+		return result
+	}
+
+	user, err := u.repository.GetByID(payload.ID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if user == nil {
+		result.Error = httpError.NewBadRequest("user not found")
+		return result
+	}
+
+	err = Transition(u.repository, user, status, reason, actor.Actor())
+	if err != nil {
+		result.Error = TransitionError(err)
+		return result
+	}
+
+	if status != model.UserStatusActive {
+		if err := u.sessionUsecase.RevokeUser(ctx, user.ID); err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+	}
+
+	u.logger.Info(ctx, "auth.changeStatus", "changeStatus", "user status changed", zap.Int64("user_id", user.ID),
+		zap.String("status", status), zap.String("changed_by", actor.Actor()))
+
+	result.Data = user
+
+	return result
+}
+
+func (u *usecase) StatusHistory(ctx context.Context, id int64) (result utils.Result) {
+	user, err := u.repository.GetByIDWithDeleted(id)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if user == nil {
+		result.Error = httpError.NewBadRequest("user not found")
+		return result
+	}
+
+	changes, err := u.repository.GetStatusChanges(id)
 	if err != nil {
 		result.Error = httpError.NewInternalServerError(err.Error())
 		return result
 	}
 
+	result.Data = changes
+
 	return result
 }
@@ -0,0 +1,47 @@
+package keys
+
+import (
+	"net/http"
+
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Handler interface {
+	ListKeys(c echo.Context) error
+	Rotate(c echo.Context) error
+}
+
+type handler struct {
+	usecase Usecase
+	tc      trace.Tracer
+}
+
+func NewHandler(usecase Usecase, tc trace.Tracer) Handler {
+	return &handler{usecase, tc}
+}
+
+func (h *handler) ListKeys(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.ListKeys")
+	defer span.End()
+
+	result := h.usecase.ListKeys(ctx)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "List Signing Key", http.StatusOK, c)
+}
+
+func (h *handler) Rotate(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Rotate")
+	defer span.End()
+
+	result := h.usecase.Rotate(ctx)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Rotate Signing Key", http.StatusOK, c)
+}
@@ -0,0 +1,185 @@
+package keys
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/helyus1412/auth-service/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// advisory lock id serializing key rotation across replicas
+const rotationLockID = 727204
+
+var ErrNoNextKey = errors.New("no next signing key to promote")
+
+type Repository interface {
+	GetAll() ([]model.SigningKey, error)
+	GetPublished(time.Time) ([]model.SigningKey, error)
+	GetActive() (*model.SigningKey, error)
+	Initialize(active *model.SigningKey, next *model.SigningKey) (bool, error)
+	Rotate(next *model.SigningKey, retireAt time.Time, activatedBefore *time.Time) (bool, error)
+}
+
+type repository struct {
+	db     *sqlx.DB
+	schema string
+}
+
+func NewRepository(db *sqlx.DB, schema string) Repository {
+	if schema == "" {
+		schema = "public"
+	}
+
+	return &repository{db, schema}
+}
+
+func (r *repository) GetAll() (keys []model.SigningKey, err error) {
+	query, err := r.db.Preparex(fmt.Sprintf(`select * from %s.signing_keys order by id desc`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Select(&keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// GetPublished returns the next and active keys plus retired keys whose grace
+// period has not yet ended at the given time.
+func (r *repository) GetPublished(at time.Time) (keys []model.SigningKey, err error) {
+	query, err := r.db.Preparex(fmt.Sprintf(`select * from %s.signing_keys
+		where state <> 'retired' or retired_at > $1 order by id desc`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Select(&keys, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *repository) GetActive() (*model.SigningKey, error) {
+	var res model.SigningKey
+
+	query, err := r.db.Preparex(fmt.Sprintf(`SELECT * from %s.signing_keys where state = 'active'`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Get(&res)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// Initialize stores the first active and next keys. It is a no-op returning
+// false when an active key already exists.
+func (r *repository) Initialize(active *model.SigningKey, next *model.SigningKey) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`select pg_advisory_xact_lock($1)`, rotationLockID); err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err = tx.Get(&exists, fmt.Sprintf(`select exists (select 1 from %s.signing_keys where state = 'active')`, r.schema))
+	if err != nil {
+		return false, err
+	}
+
+	if exists {
+		return false, nil
+	}
+
+	now := time.Now()
+	active.State = model.SigningKeyStateActive
+	active.ActivatedAt = &now
+	next.State = model.SigningKeyStateNext
+
+	for _, key := range []*model.SigningKey{active, next} {
+		if err := r.insert(tx, key); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+// Rotate promotes the next key to active, retires the current active key as of
+// retireAt and stores a new next key. When activatedBefore is set the rotation
+// only happens if the active key was activated before that time, which keeps
+// scheduled rotations on several replicas from rotating twice.
+func (r *repository) Rotate(next *model.SigningKey, retireAt time.Time, activatedBefore *time.Time) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`select pg_advisory_xact_lock($1)`, rotationLockID); err != nil {
+		return false, err
+	}
+
+	if activatedBefore != nil {
+		var due bool
+		err = tx.Get(&due, fmt.Sprintf(`select exists (select 1 from %s.signing_keys
+			where state = 'active' and activated_at < $1)`, r.schema), *activatedBefore)
+		if err != nil {
+			return false, err
+		}
+
+		if !due {
+			return false, nil
+		}
+	}
+
+	var nextID int64
+	err = tx.Get(&nextID, fmt.Sprintf(`select id from %s.signing_keys where state = 'next' order by id limit 1`, r.schema))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, ErrNoNextKey
+	}
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(fmt.Sprintf(`update %s.signing_keys set state = 'retired', retired_at = $1 where state = 'active'`, r.schema), retireAt)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(fmt.Sprintf(`update %s.signing_keys set state = 'active', activated_at = $1 where id = $2`, r.schema), time.Now(), nextID)
+	if err != nil {
+		return false, err
+	}
+
+	next.State = model.SigningKeyStateNext
+	if err := r.insert(tx, next); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *repository) insert(tx *sqlx.Tx, key *model.SigningKey) error {
+	return tx.QueryRowx(fmt.Sprintf(`INSERT INTO %s.signing_keys (kid, algorithm, private_key_encrypted, public_key, state, activated_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`, r.schema),
+		key.KeyID, key.Algorithm, key.PrivateKeyEncrypted, key.PublicKey, key.State, key.ActivatedAt).Scan(&key.ID, &key.CreatedAt)
+}
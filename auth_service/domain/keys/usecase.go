@@ -0,0 +1,362 @@
+package keys
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/helyus1412/auth-service/model"
+	"github.com/helyus1412/auth-service/pkg/encryption"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"github.com/helyus1412/auth-service/pkg/token"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"go.uber.org/zap"
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// minimum time between reloads triggered by tokens carrying an unknown kid
+const missReloadInterval = 10 * time.Second
+
+// ReloadInterval is how often RunScheduler reloads the keys, and so how long a
+// replica may keep signing with a key another replica already rotated out.
+const ReloadInterval = time.Minute
+
+// Usecase manages signing keys and serves them to the token manager.
+type Usecase interface {
+	token.KeySet
+	// Bootstrap creates the first active and next keys when none exist. When
+	// bootstrapKey is set it becomes the first active key.
+	Bootstrap(ctx context.Context, bootstrapKey crypto.Signer) error
+	// Reload refreshes the in-memory key cache from the database.
+	Reload(ctx context.Context) error
+	// RunScheduler rotates keys whenever the active key is older than the
+	// rotation interval and keeps the cache fresh. It blocks until ctx is done.
+	RunScheduler(ctx context.Context)
+	Rotate(context.Context) utils.Result
+	ListKeys(context.Context) utils.Result
+}
+
+type Config struct {
+	Algorithm        string
+	RotationInterval time.Duration
+	// RetirementGrace is how long a rotated-out key keeps verifying tokens; it
+	// must be at least the lifetime of the tokens it signed, see
+	// RetirementGrace.
+	RetirementGrace time.Duration
+}
+
+// RetirementGrace returns the grace covering tokens with the given lifetimes,
+// including those signed by replicas that have not reloaded the keys yet.
+func RetirementGrace(tokenTTLs ...time.Duration) time.Duration {
+	var longest time.Duration
+	for _, ttl := range tokenTTLs {
+		longest = max(longest, ttl)
+	}
+
+	return longest + ReloadInterval
+}
+
+type usecase struct {
+	repository Repository
+	cipher     *encryption.Cipher
+	config     Config
+	logger     *logger.Logger
+
+	mu         sync.RWMutex
+	signing    *token.SigningKey
+	published  map[string]cachedKey
+	lastReload time.Time
+}
+
+type cachedKey struct {
+	key       token.VerificationKey
+	retiredAt *time.Time
+}
+
+func NewUsecase(repository Repository, cipher *encryption.Cipher, config Config, logger *logger.Logger) Usecase {
+	return &usecase{
+		repository: repository,
+		cipher:     cipher,
+		config:     config,
+		logger:     logger,
+		published:  map[string]cachedKey{},
+	}
+}
+
+func (u *usecase) Bootstrap(ctx context.Context, bootstrapKey crypto.Signer) error {
+	active, err := u.repository.GetActive()
+	if err != nil {
+		return err
+	}
+
+	if active == nil {
+		if bootstrapKey == nil {
+			bootstrapKey, err = token.GenerateKey(u.config.Algorithm)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := token.CheckKey(u.config.Algorithm, bootstrapKey); err != nil {
+			return err
+		}
+
+		activeKey, err := u.newKey(bootstrapKey)
+		if err != nil {
+			return err
+		}
+
+		nextKey, err := u.generateKey()
+		if err != nil {
+			return err
+		}
+
+		created, err := u.repository.Initialize(activeKey, nextKey)
+		if err != nil {
+			return err
+		}
+
+		if created {
+			u.logger.Info(ctx, "keys.Bootstrap", "Initialize", "created initial signing keys",
+				zap.String("active_kid", activeKey.KeyID),
+				zap.String("next_kid", nextKey.KeyID),
+			)
+		}
+	}
+
+	return u.Reload(ctx)
+}
+
+func (u *usecase) Reload(ctx context.Context) error {
+	now := time.Now()
+
+	keys, err := u.repository.GetPublished(now)
+	if err != nil {
+		return err
+	}
+
+	var signing *token.SigningKey
+	published := make(map[string]cachedKey, len(keys))
+
+	for _, key := range keys {
+		publicKey, err := x509.ParsePKIXPublicKey(key.PublicKey)
+		if err != nil {
+			return err
+		}
+
+		published[key.KeyID] = cachedKey{
+			key: token.VerificationKey{
+				KeyID:     key.KeyID,
+				Algorithm: key.Algorithm,
+				PublicKey: publicKey,
+			},
+			retiredAt: key.RetiredAt,
+		}
+
+		if key.State == model.SigningKeyStateActive {
+			der, err := u.cipher.Decrypt(key.PrivateKeyEncrypted)
+			if err != nil {
+				return err
+			}
+
+			privateKey, err := token.ParsePrivateKey(der)
+			if err != nil {
+				return err
+			}
+
+			signing = &token.SigningKey{
+				KeyID:      key.KeyID,
+				Algorithm:  key.Algorithm,
+				PrivateKey: privateKey,
+			}
+		}
+	}
+
+	if signing == nil {
+		return errors.New("no active signing key")
+	}
+
+	u.mu.Lock()
+	u.signing = signing
+	u.published = published
+	u.lastReload = now
+	u.mu.Unlock()
+
+	return nil
+}
+
+func (u *usecase) SigningKey() (*token.SigningKey, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	if u.signing == nil {
+		return nil, errors.New("signing keys are not loaded")
+	}
+
+	return u.signing, nil
+}
+
+func (u *usecase) VerificationKey(kid string) (*token.VerificationKey, error) {
+	key, ok := u.lookup(kid)
+	if !ok {
+		// another replica may have rotated since our last reload
+		u.mu.RLock()
+		stale := time.Since(u.lastReload) > missReloadInterval
+		u.mu.RUnlock()
+
+		if stale {
+			if err := u.Reload(context.Background()); err != nil {
+				return nil, err
+			}
+			key, ok = u.lookup(kid)
+		}
+	}
+
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+func (u *usecase) lookup(kid string) (*token.VerificationKey, bool) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	cached, ok := u.published[kid]
+	if !ok || cached.retiredAt != nil && time.Now().After(*cached.retiredAt) {
+		return nil, false
+	}
+
+	key := cached.key
+
+	return &key, true
+}
+
+func (u *usecase) VerificationKeys() ([]token.VerificationKey, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	now := time.Now()
+	keys := make([]token.VerificationKey, 0, len(u.published))
+	for _, cached := range u.published {
+		if cached.retiredAt != nil && now.After(*cached.retiredAt) {
+			continue
+		}
+		keys = append(keys, cached.key)
+	}
+
+	return keys, nil
+}
+
+func (u *usecase) RunScheduler(ctx context.Context) {
+	ticker := time.NewTicker(ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if u.config.RotationInterval > 0 {
+				dueBefore := time.Now().Add(-u.config.RotationInterval)
+				if _, err := u.rotate(ctx, &dueBefore); err != nil {
+					u.logger.Error(ctx, "keys.RunScheduler", "Rotate", "scheduled signing key rotation failed", err)
+				}
+			}
+
+			if err := u.Reload(ctx); err != nil {
+				u.logger.Error(ctx, "keys.RunScheduler", "Reload", "failed to reload signing keys", err)
+			}
+		}
+	}
+}
+
+func (u *usecase) Rotate(ctx context.Context) (result utils.Result) {
+	if _, err := u.rotate(ctx, nil); err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if err := u.Reload(ctx); err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	return u.ListKeys(ctx)
+}
+
+func (u *usecase) rotate(ctx context.Context, activatedBefore *time.Time) (bool, error) {
+	next, err := u.generateKey()
+	if err != nil {
+		return false, err
+	}
+
+	rotated, err := u.repository.Rotate(next, time.Now().Add(u.config.RetirementGrace), activatedBefore)
+	if err != nil {
+		return false, err
+	}
+
+	if rotated {
+		u.logger.Info(ctx, "keys.Rotate", "Rotated", "signing keys rotated",
+			zap.String("next_kid", next.KeyID),
+		)
+	}
+
+	return rotated, nil
+}
+
+func (u *usecase) ListKeys(ctx context.Context) (result utils.Result) {
+	keys, err := u.repository.GetAll()
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	result.Data = keys
+
+	return result
+}
+
+func (u *usecase) generateKey() (*model.SigningKey, error) {
+	privateKey, err := token.GenerateKey(u.config.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.newKey(privateKey)
+}
+
+func (u *usecase) newKey(privateKey crypto.Signer) (*model.SigningKey, error) {
+	kid, err := token.Thumbprint(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := u.cipher.Encrypt(der)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.SigningKey{
+		KeyID:               kid,
+		Algorithm:           u.config.Algorithm,
+		PrivateKeyEncrypted: encrypted,
+		PublicKey:           publicKey,
+	}, nil
+}
@@ -0,0 +1,96 @@
+package mfa
+
+import (
+	"net/http"
+
+	"github.com/helyus1412/auth-service/dto"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Handler interface {
+	Enroll(c echo.Context) error
+	Activate(c echo.Context) error
+	Disable(c echo.Context) error
+	RegenerateRecoveryCodes(c echo.Context) error
+}
+
+type handler struct {
+	usecase Usecase
+	tc      trace.Tracer
+}
+
+func NewHandler(usecase Usecase, tc trace.Tracer) Handler {
+	return &handler{usecase, tc}
+}
+
+func (h *handler) Enroll(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Enroll")
+	defer span.End()
+
+	result := h.usecase.Enroll(ctx)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Enroll Authenticator", http.StatusOK, c)
+}
+
+func (h *handler) Activate(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Activate")
+	defer span.End()
+
+	var payload dto.TOTPCodeRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.Activate(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Activate Authenticator", http.StatusOK, c)
+}
+
+func (h *handler) Disable(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Disable")
+	defer span.End()
+
+	var payload dto.TOTPCodeRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.Disable(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Disable Authenticator", http.StatusOK, c)
+}
+
+func (h *handler) RegenerateRecoveryCodes(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.RegenerateRecoveryCodes")
+	defer span.End()
+
+	var payload dto.TOTPCodeRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.RegenerateRecoveryCodes(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Regenerate Recovery Codes", http.StatusOK, c)
+}
@@ -0,0 +1,158 @@
+package mfa
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/helyus1412/auth-service/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	GetTOTP(int64) (*model.UserTOTP, error)
+	UpsertTOTP(*model.UserTOTP) error
+	ConfirmTOTP(userID int64, step int64) error
+	UpdateLastUsedStep(userID int64, step int64) (bool, error)
+	DeleteTOTP(int64) error
+	ReplaceRecoveryCodes(userID int64, hashes []string) error
+	UseRecoveryCode(userID int64, hash string) (bool, error)
+}
+
+type repository struct {
+	db     *sqlx.DB
+	schema string
+}
+
+func NewRepository(db *sqlx.DB, schema string) Repository {
+	if schema == "" {
+		schema = "public"
+	}
+
+	return &repository{db, schema}
+}
+
+func (r *repository) GetTOTP(userID int64) (*model.UserTOTP, error) {
+	var res model.UserTOTP
+
+	query, err := r.db.Preparex(fmt.Sprintf(`SELECT * from %s.user_totp where user_id = $1`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Get(&res, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// UpsertTOTP stores a pending (unconfirmed) secret, replacing any earlier pending one.
+func (r *repository) UpsertTOTP(totp *model.UserTOTP) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`INSERT INTO %s.user_totp (user_id, secret_encrypted) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET secret_encrypted = EXCLUDED.secret_encrypted, last_used_step = 0,
+		confirmed_at = NULL, updated_at = NOW()`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(totp.UserID, totp.SecretEncrypted)
+
+	return err
+}
+
+func (r *repository) ConfirmTOTP(userID int64, step int64) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.user_totp set confirmed_at = $1, last_used_step = $2, updated_at = $1 where user_id = $3`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(time.Now(), step, userID)
+
+	return err
+}
+
+// UpdateLastUsedStep records a consumed time step. It reports false when an
+// equal or later step was already used, i.e. the code is being replayed.
+func (r *repository) UpdateLastUsedStep(userID int64, step int64) (bool, error) {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.user_totp set last_used_step = $1 where user_id = $2 and last_used_step < $1`, r.schema))
+	if err != nil {
+		return false, err
+	}
+
+	res, err := queryPrep.Exec(step, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *repository) DeleteTOTP(userID int64) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(fmt.Sprintf(`delete from %s.user_totp where user_id = $1`, r.schema), userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf(`delete from %s.recovery_codes where user_id = $1`, r.schema), userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) ReplaceRecoveryCodes(userID int64, hashes []string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(fmt.Sprintf(`delete from %s.recovery_codes where user_id = $1`, r.schema), userID); err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		_, err := tx.Exec(fmt.Sprintf(`INSERT INTO %s.recovery_codes (user_id, code_hash) VALUES ($1, $2)`, r.schema), userID, hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseRecoveryCode consumes an unused recovery code, reporting whether one matched.
+func (r *repository) UseRecoveryCode(userID int64, hash string) (bool, error) {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.recovery_codes set used_at = $1 where user_id = $2 and code_hash = $3 and used_at is null`, r.schema))
+	if err != nil {
+		return false, err
+	}
+
+	res, err := queryPrep.Exec(time.Now(), userID, hash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
@@ -0,0 +1,287 @@
+package mfa
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"github.com/helyus1412/auth-service/dto"
+	"github.com/helyus1412/auth-service/model"
+	"github.com/helyus1412/auth-service/pkg/encryption"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"github.com/helyus1412/auth-service/pkg/principal"
+	"github.com/helyus1412/auth-service/pkg/totp"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"go.uber.org/zap"
+)
+
+const recoveryCodeCount = 10
+
+type Usecase interface {
+	Enroll(context.Context) utils.Result
+	Activate(context.Context, *dto.TOTPCodeRequest) utils.Result
+	Disable(context.Context, *dto.TOTPCodeRequest) utils.Result
+	RegenerateRecoveryCodes(context.Context, *dto.TOTPCodeRequest) utils.Result
+	// IsEnabled reports whether the user has a confirmed authenticator.
+	IsEnabled(ctx context.Context, userID int64) (bool, error)
+	// Verify checks a TOTP code or, when code is empty, a recovery code.
+	Verify(ctx context.Context, userID int64, code string, recoveryCode string) (bool, error)
+}
+
+type usecase struct {
+	repository Repository
+	cipher     *encryption.Cipher
+	issuer     string
+	logger     *logger.Logger
+}
+
+func NewUsecase(repository Repository, cipher *encryption.Cipher, issuer string, logger *logger.Logger) Usecase {
+	return &usecase{repository, cipher, issuer, logger}
+}
+
+func (u *usecase) Enroll(ctx context.Context) (result utils.Result) {
+	caller, ok := principal.FromContext(ctx)
+	if !ok {
+		result.Error = httpError.NewUnauthorized("")
+		return result
+	}
+
+	existing, err := u.repository.GetTOTP(caller.UserID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if existing != nil && existing.ConfirmedAt != nil {
+		result.Error = httpError.NewConflict("an authenticator is already enrolled")
+		return result
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	encrypted, err := u.cipher.Encrypt([]byte(secret))
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	err = u.repository.UpsertTOTP(&model.UserTOTP{
+		UserID:          caller.UserID,
+		SecretEncrypted: encrypted,
+	})
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	result.Data = &dto.TOTPEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(u.issuer, caller.Email, secret),
+	}
+
+	return result
+}
+
+// Activate confirms a pending enrollment with a first valid code and returns
+// the user's recovery codes.
+func (u *usecase) Activate(ctx context.Context, payload *dto.TOTPCodeRequest) (result utils.Result) {
+	caller, ok := principal.FromContext(ctx)
+	if !ok {
+		result.Error = httpError.NewUnauthorized("")
+		return result
+	}
+
+	enrollment, err := u.repository.GetTOTP(caller.UserID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if enrollment == nil {
+		result.Error = httpError.NewBadRequest("no pending enrollment, call enroll first")
+		return result
+	}
+
+	if enrollment.ConfirmedAt != nil {
+		result.Error = httpError.NewConflict("an authenticator is already enrolled")
+		return result
+	}
+
+	secret, err := u.cipher.Decrypt(enrollment.SecretEncrypted)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	step, valid := totp.Validate(string(secret), payload.Code, time.Now(), enrollment.LastUsedStep)
+	if !valid {
+		result.Error = httpError.NewBadRequest("invalid code")
+		return result
+	}
+
+	err = u.repository.ConfirmTOTP(caller.UserID, step)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	codes, err := u.replaceRecoveryCodes(caller.UserID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	u.logger.Info(ctx, "mfa.Activate", "Enrolled", "totp authenticator enrolled", zap.Int64("user_id", caller.UserID))
+
+	result.Data = &dto.RecoveryCodesResponse{RecoveryCodes: codes}
+
+	return result
+}
+
+func (u *usecase) Disable(ctx context.Context, payload *dto.TOTPCodeRequest) (result utils.Result) {
+	caller, ok := principal.FromContext(ctx)
+	if !ok {
+		result.Error = httpError.NewUnauthorized("")
+		return result
+	}
+
+	valid, err := u.Verify(ctx, caller.UserID, payload.Code, "")
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if !valid {
+		result.Error = httpError.NewBadRequest("invalid code")
+		return result
+	}
+
+	err = u.repository.DeleteTOTP(caller.UserID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	u.logger.Warn(ctx, "mfa.Disable", "Disabled", "totp authenticator removed", zap.Int64("user_id", caller.UserID))
+
+	return result
+}
+
+func (u *usecase) RegenerateRecoveryCodes(ctx context.Context, payload *dto.TOTPCodeRequest) (result utils.Result) {
+	caller, ok := principal.FromContext(ctx)
+	if !ok {
+		result.Error = httpError.NewUnauthorized("")
+		return result
+	}
+
+	valid, err := u.Verify(ctx, caller.UserID, payload.Code, "")
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if !valid {
+		result.Error = httpError.NewBadRequest("invalid code")
+		return result
+	}
+
+	codes, err := u.replaceRecoveryCodes(caller.UserID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	result.Data = &dto.RecoveryCodesResponse{RecoveryCodes: codes}
+
+	return result
+}
+
+func (u *usecase) IsEnabled(ctx context.Context, userID int64) (bool, error) {
+	enrollment, err := u.repository.GetTOTP(userID)
+	if err != nil {
+		return false, err
+	}
+
+	return enrollment != nil && enrollment.ConfirmedAt != nil, nil
+}
+
+func (u *usecase) Verify(ctx context.Context, userID int64, code string, recoveryCode string) (bool, error) {
+	enrollment, err := u.repository.GetTOTP(userID)
+	if err != nil {
+		return false, err
+	}
+
+	if enrollment == nil || enrollment.ConfirmedAt == nil {
+		return false, nil
+	}
+
+	if code == "" {
+		used, err := u.repository.UseRecoveryCode(userID, utils.HashToken(normalizeRecoveryCode(recoveryCode)))
+		if err != nil {
+			return false, err
+		}
+
+		if used {
+			u.logger.Warn(ctx, "mfa.Verify", "RecoveryCodeUsed", "recovery code consumed", zap.Int64("user_id", userID))
+		}
+
+		return used, nil
+	}
+
+	secret, err := u.cipher.Decrypt(enrollment.SecretEncrypted)
+	if err != nil {
+		return false, err
+	}
+
+	step, valid := totp.Validate(string(secret), code, time.Now(), enrollment.LastUsedStep)
+	if !valid {
+		return false, nil
+	}
+
+	// guards against the same code being accepted twice concurrently
+	return u.repository.UpdateLastUsedStep(userID, step)
+}
+
+func (u *usecase) replaceRecoveryCodes(userID int64) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashToken(normalizeRecoveryCode(code)))
+	}
+
+	if err := u.repository.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// generateRecoveryCode returns a 10 character code formatted as xxxxx-xxxxx.
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 7)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	raw := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))[:10]
+
+	return raw[:5] + "-" + raw[5:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
@@ -0,0 +1,47 @@
+package oauth
+
+import "net/http"
+
+// Error is an RFC 6749 error response. Token endpoint clients expect this
+// shape rather than the service's BaseWrapperModel envelope.
+type Error struct {
+	Status      int    `json:"-"`
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e Error) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func newError(status int, code, description string) Error {
+	return Error{Status: status, Code: code, Description: description}
+}
+
+func invalidRequest(description string) Error {
+	return newError(http.StatusBadRequest, "invalid_request", description)
+}
+
+func invalidClient(description string) Error {
+	return newError(http.StatusUnauthorized, "invalid_client", description)
+}
+
+func invalidGrant(description string) Error {
+	return newError(http.StatusBadRequest, "invalid_grant", description)
+}
+
+func unauthorizedClient(description string) Error {
+	return newError(http.StatusBadRequest, "unauthorized_client", description)
+}
+
+func unsupportedGrantType(description string) Error {
+	return newError(http.StatusBadRequest, "unsupported_grant_type", description)
+}
+
+func invalidScope(description string) Error {
+	return newError(http.StatusBadRequest, "invalid_scope", description)
+}
+
+func serverError(err error) Error {
+	return newError(http.StatusInternalServerError, "server_error", err.Error())
+}
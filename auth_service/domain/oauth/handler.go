@@ -0,0 +1,243 @@
+package oauth
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/helyus1412/auth-service/dto"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Handler interface {
+	Authorize(c echo.Context) error
+	Approve(c echo.Context) error
+	Token(c echo.Context) error
+	Revoke(c echo.Context) error
+	Introspect(c echo.Context) error
+	ListClients(c echo.Context) error
+	GetClient(c echo.Context) error
+	CreateClient(c echo.Context) error
+	DeleteClient(c echo.Context) error
+}
+
+type handler struct {
+	usecase Usecase
+	tc      trace.Tracer
+}
+
+func NewHandler(usecase Usecase, tc trace.Tracer) Handler {
+	return &handler{usecase, tc}
+}
+
+func (h *handler) Authorize(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Authorize")
+	defer span.End()
+
+	var payload dto.AuthorizeRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.Authorize(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	if redirect, ok := result.Data.(*dto.AuthorizeRedirect); ok {
+		return c.Redirect(http.StatusFound, redirect.URL)
+	}
+
+	return utils.Response(result.Data, "Authorization Request", http.StatusOK, c)
+}
+
+func (h *handler) Approve(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Approve")
+	defer span.End()
+
+	var payload dto.AuthorizeRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	payload.IP = c.RealIP()
+	payload.UserAgent = c.Request().UserAgent()
+
+	result := h.usecase.Approve(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	if challenge, ok := result.Data.(*dto.MFAChallengeResponse); ok {
+		return utils.Response(challenge, "MFA Required", http.StatusOK, c)
+	}
+
+	redirect := result.Data.(*dto.AuthorizeRedirect)
+
+	return c.Redirect(http.StatusFound, redirect.URL)
+}
+
+func (h *handler) Token(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Token")
+	defer span.End()
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+
+	var payload dto.OAuthTokenRequest
+
+	if err := c.Bind(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, invalidRequest("malformed request body"))
+	}
+
+	// client_secret_basic takes precedence over client_secret_post
+	if clientID, clientSecret, ok := c.Request().BasicAuth(); ok {
+		payload.ClientID, _ = url.QueryUnescape(clientID)
+		payload.ClientSecret, _ = url.QueryUnescape(clientSecret)
+	}
+
+	result := h.usecase.Token(ctx, &payload)
+	if result.Error != nil {
+		return tokenError(result.Error, c)
+	}
+
+	return c.JSON(http.StatusOK, result.Data)
+}
+
+func (h *handler) Revoke(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Revoke")
+	defer span.End()
+
+	var payload dto.OAuthRevokeRequest
+
+	if err := c.Bind(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, invalidRequest("malformed request body"))
+	}
+
+	if clientID, clientSecret, ok := c.Request().BasicAuth(); ok {
+		payload.ClientID, _ = url.QueryUnescape(clientID)
+		payload.ClientSecret, _ = url.QueryUnescape(clientSecret)
+	}
+
+	result := h.usecase.Revoke(ctx, &payload)
+	if result.Error != nil {
+		return tokenError(result.Error, c)
+	}
+
+	// RFC 7009 answers with an empty 200 whether or not the token was valid
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *handler) Introspect(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Introspect")
+	defer span.End()
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+
+	var payload dto.OAuthIntrospectRequest
+
+	if err := c.Bind(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, invalidRequest("malformed request body"))
+	}
+
+	if clientID, clientSecret, ok := c.Request().BasicAuth(); ok {
+		payload.ClientID, _ = url.QueryUnescape(clientID)
+		payload.ClientSecret, _ = url.QueryUnescape(clientSecret)
+	}
+
+	result := h.usecase.Introspect(ctx, &payload)
+	if result.Error != nil {
+		return tokenError(result.Error, c)
+	}
+
+	return c.JSON(http.StatusOK, result.Data)
+}
+
+func (h *handler) ListClients(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.ListClients")
+	defer span.End()
+
+	result := h.usecase.ListClients(ctx)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "List Client", http.StatusOK, c)
+}
+
+func (h *handler) GetClient(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.GetClient")
+	defer span.End()
+
+	var payload dto.ClientIDRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.GetClient(ctx, payload.ClientID)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Detail Client", http.StatusOK, c)
+}
+
+func (h *handler) CreateClient(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.CreateClient")
+	defer span.End()
+
+	var payload dto.CreateClientRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.CreateClient(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Create Client", http.StatusCreated, c)
+}
+
+func (h *handler) DeleteClient(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.DeleteClient")
+	defer span.End()
+
+	var payload dto.ClientIDRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.DeleteClient(ctx, payload.ClientID)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Delete Client", http.StatusOK, c)
+}
+
+// tokenError renders RFC 6749 errors as-is and falls back to the standard
+// envelope for anything else.
+func tokenError(err interface{}, c echo.Context) error {
+	oauthErr, ok := err.(Error)
+	if !ok {
+		return utils.ResponseError(err, c)
+	}
+
+	if oauthErr.Status == http.StatusUnauthorized {
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+	}
+
+	return c.JSON(oauthErr.Status, oauthErr)
+}
@@ -0,0 +1,173 @@
+package oauth
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/helyus1412/auth-service/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	InsertClient(*model.OAuthClient) error
+	GetClients() ([]model.OAuthClient, error)
+	GetClientByClientID(string) (*model.OAuthClient, error)
+	SoftDeleteClient(string) error
+	InsertCode(*model.AuthorizationCode) error
+	GetCodeByHash(string) (*model.AuthorizationCode, error)
+	MarkCodeUsed(int64) (bool, error)
+	GetConsent(userID int64, clientID string) (*model.Consent, error)
+	UpsertConsent(*model.Consent) error
+}
+
+type repository struct {
+	db     *sqlx.DB
+	schema string
+}
+
+func NewRepository(db *sqlx.DB, schema string) Repository {
+	if schema == "" {
+		schema = "public"
+	}
+
+	return &repository{db, schema}
+}
+
+func (r *repository) InsertClient(client *model.OAuthClient) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`INSERT INTO %s.oauth_clients (client_id, client_secret_hash, name, redirect_uris, grant_types, scopes, is_confidential)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	return queryPrep.QueryRowx(client.ClientID, client.ClientSecretHash, client.Name, client.RedirectURIs,
+		client.GrantTypes, client.Scopes, client.IsConfidential).Scan(&client.ID, &client.CreatedAt)
+}
+
+func (r *repository) GetClients() (clients []model.OAuthClient, err error) {
+	query, err := r.db.Preparex(fmt.Sprintf(`select * from %s.oauth_clients where deleted_at is null order by id`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Select(&clients)
+	if err != nil {
+		return nil, err
+	}
+
+	return clients, nil
+}
+
+func (r *repository) GetClientByClientID(clientID string) (*model.OAuthClient, error) {
+	var res model.OAuthClient
+
+	query, err := r.db.Preparex(fmt.Sprintf(`SELECT * from %s.oauth_clients where client_id = $1 and deleted_at is null`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Get(&res, clientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *repository) SoftDeleteClient(clientID string) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.oauth_clients set deleted_at = $1 where client_id = $2`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(time.Now(), clientID)
+
+	return err
+}
+
+func (r *repository) InsertCode(code *model.AuthorizationCode) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`INSERT INTO %s.oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, redirect_uri_explicit, scope, code_challenge, code_challenge_method, nonce, auth_time, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, created_at`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	return queryPrep.QueryRowx(code.CodeHash, code.ClientID, code.UserID, code.RedirectURI, code.RedirectURIExplicit, code.Scope,
+		code.CodeChallenge, code.CodeChallengeMethod, code.Nonce, code.AuthTime, code.ExpiresAt).Scan(&code.ID, &code.CreatedAt)
+}
+
+func (r *repository) GetCodeByHash(hash string) (*model.AuthorizationCode, error) {
+	var res model.AuthorizationCode
+
+	query, err := r.db.Preparex(fmt.Sprintf(`SELECT * from %s.oauth_authorization_codes where code_hash = $1`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Get(&res, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// MarkCodeUsed redeems an authorization code. It reports false when the code
+// had already been redeemed.
+func (r *repository) MarkCodeUsed(id int64) (bool, error) {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.oauth_authorization_codes set used_at = $1 where id = $2 and used_at is null`, r.schema))
+	if err != nil {
+		return false, err
+	}
+
+	res, err := queryPrep.Exec(time.Now(), id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *repository) GetConsent(userID int64, clientID string) (*model.Consent, error) {
+	var res model.Consent
+
+	query, err := r.db.Preparex(fmt.Sprintf(`SELECT * from %s.oauth_consents where user_id = $1 and client_id = $2`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Get(&res, userID, clientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *repository) UpsertConsent(consent *model.Consent) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`INSERT INTO %s.oauth_consents (user_id, client_id, scope) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, client_id) DO UPDATE SET scope = EXCLUDED.scope, updated_at = NOW()`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(consent.UserID, consent.ClientID, consent.Scope)
+
+	return err
+}
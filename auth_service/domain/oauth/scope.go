@@ -0,0 +1,50 @@
+package oauth
+
+import "strings"
+
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken      = "refresh_token"
+	GrantClientCredentials = "client_credentials"
+)
+
+func parseScope(scope string) []string {
+	return strings.Fields(scope)
+}
+
+func joinScope(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSubset reports whether every element of requested is present in allowed.
+func isSubset(requested, allowed []string) bool {
+	for _, scope := range requested {
+		if !contains(allowed, scope) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mergeScopes returns the union of two scope lists, preserving order.
+func mergeScopes(a, b []string) []string {
+	merged := append([]string{}, a...)
+	for _, scope := range b {
+		if !contains(merged, scope) {
+			merged = append(merged, scope)
+		}
+	}
+
+	return merged
+}
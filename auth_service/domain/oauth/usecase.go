@@ -0,0 +1,935 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/helyus1412/auth-service/domain/auth"
+	"github.com/helyus1412/auth-service/domain/oidc"
+	"github.com/helyus1412/auth-service/domain/rbac"
+	"github.com/helyus1412/auth-service/domain/refresh"
+	"github.com/helyus1412/auth-service/dto"
+	"github.com/helyus1412/auth-service/model"
+	"github.com/helyus1412/auth-service/pkg/denylist"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"github.com/helyus1412/auth-service/pkg/token"
+	"github.com/helyus1412/auth-service/pkg/utils"
+)
+
+type Usecase interface {
+	Authorize(context.Context, *dto.AuthorizeRequest) utils.Result
+	Approve(context.Context, *dto.AuthorizeRequest) utils.Result
+	Token(context.Context, *dto.OAuthTokenRequest) utils.Result
+	Revoke(context.Context, *dto.OAuthRevokeRequest) utils.Result
+	Introspect(context.Context, *dto.OAuthIntrospectRequest) utils.Result
+	ListClients(context.Context) utils.Result
+	GetClient(context.Context, string) utils.Result
+	CreateClient(context.Context, *dto.CreateClientRequest) utils.Result
+	DeleteClient(context.Context, string) utils.Result
+}
+
+type usecase struct {
+	repository     Repository
+	authRepository auth.Repository
+	rbacRepository rbac.Repository
+	refreshUsecase refresh.Usecase
+	tokenManager   *token.Manager
+	denylist       denylist.Denylist
+	authUsecase    auth.Usecase
+	codeTTL        time.Duration
+	logger         *logger.Logger
+}
+
+func NewUsecase(repository Repository, authRepository auth.Repository, rbacRepository rbac.Repository,
+	refreshUsecase refresh.Usecase, tokenManager *token.Manager, denylist denylist.Denylist, authUsecase auth.Usecase,
+	codeTTL time.Duration, logger *logger.Logger) Usecase {
+	return &usecase{repository, authRepository, rbacRepository, refreshUsecase, tokenManager, denylist, authUsecase,
+		codeTTL, logger}
+}
+
+// authorizeRequest is a validated /oauth/authorize request.
+type authorizeRequest struct {
+	client      *model.OAuthClient
+	redirectURI string
+	scopes      []string
+}
+
+func (u *usecase) Authorize(ctx context.Context, payload *dto.AuthorizeRequest) (result utils.Result) {
+	request, redirect, respErr := u.validateAuthorize(payload)
+	if respErr != nil {
+		result.Error = respErr
+		return result
+	}
+
+	if redirect != nil {
+		result.Data = redirect
+		return result
+	}
+
+	result.Data = &dto.AuthorizeResponse{
+		ClientID:        request.client.ClientID,
+		ClientName:      request.client.Name,
+		RedirectURI:     request.redirectURI,
+		Scopes:          request.scopes,
+		State:           payload.State,
+		ConsentRequired: true,
+	}
+
+	return result
+}
+
+// Approve authenticates the resource owner, records consent and issues an
+// authorization code, returning the redirect back to the client. Users with a
+// second factor get a *dto.MFAChallengeResponse first and repeat the request
+// with its token.
+func (u *usecase) Approve(ctx context.Context, payload *dto.AuthorizeRequest) (result utils.Result) {
+	request, redirect, respErr := u.validateAuthorize(payload)
+	if respErr != nil {
+		result.Error = respErr
+		return result
+	}
+
+	if redirect != nil {
+		result.Data = redirect
+		return result
+	}
+
+	user, authenticated := u.authenticateOwner(ctx, payload)
+	if user == nil {
+		return authenticated
+	}
+
+	switch payload.Decision {
+	case "deny":
+		result.Data = errorRedirect(request.redirectURI, payload.State, "access_denied", "the resource owner denied the request")
+		return result
+	case "approve":
+		consent, err := u.repository.GetConsent(user.ID, request.client.ClientID)
+		if err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+
+		granted := request.scopes
+		if consent != nil {
+			granted = mergeScopes(parseScope(consent.Scope), request.scopes)
+		}
+
+		err = u.repository.UpsertConsent(&model.Consent{
+			UserID:   user.ID,
+			ClientID: request.client.ClientID,
+			Scope:    joinScope(granted),
+		})
+		if err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+	default:
+		consent, err := u.repository.GetConsent(user.ID, request.client.ClientID)
+		if err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+
+		if consent == nil || !isSubset(request.scopes, parseScope(consent.Scope)) {
+			result.Error = httpError.NewCustomError(http.StatusForbidden, "consent_required", "the user must approve the requested scopes")
+			return result
+		}
+	}
+
+	code, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	authorizationCode := &model.AuthorizationCode{
+		CodeHash:            utils.HashToken(code),
+		ClientID:            request.client.ClientID,
+		UserID:              user.ID,
+		RedirectURI:         request.redirectURI,
+		RedirectURIExplicit: payload.RedirectURI != "",
+		Scope:               joinScope(request.scopes),
+		CodeChallenge:       payload.CodeChallenge,
+		CodeChallengeMethod: payload.CodeChallengeMethod,
+		AuthTime:            time.Now(),
+		ExpiresAt:           time.Now().Add(u.codeTTL),
+	}
+
+	if payload.Nonce != "" {
+		authorizationCode.Nonce = &payload.Nonce
+	}
+
+	err = u.repository.InsertCode(authorizationCode)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	query := url.Values{"code": {code}}
+	if payload.State != "" {
+		query.Set("state", payload.State)
+	}
+
+	result.Data = &dto.AuthorizeRedirect{URL: withQuery(request.redirectURI, query)}
+
+	return result
+}
+
+// authenticateOwner signs the resource owner in exactly as /login and
+// /login/mfa do, throttling included. Without a user the result holds the
+// error or the *dto.MFAChallengeResponse to answer with.
+func (u *usecase) authenticateOwner(ctx context.Context, payload *dto.AuthorizeRequest) (*model.User, utils.Result) {
+	if payload.MFAToken != "" {
+		result := u.authUsecase.AuthenticateMFA(ctx, &dto.LoginMFARequest{
+			MFAToken:     payload.MFAToken,
+			Code:         payload.MFACode,
+			RecoveryCode: payload.RecoveryCode,
+			SessionID:    payload.SessionID,
+			Credential:   payload.Credential,
+			IP:           payload.IP,
+			UserAgent:    payload.UserAgent,
+		})
+		if result.Error != nil {
+			return nil, result
+		}
+
+		return result.Data.(*auth.Authentication).User, utils.Result{}
+	}
+
+	result := u.authUsecase.Authenticate(ctx, &dto.LoginRequest{
+		Email:     payload.Email,
+		Password:  payload.Password,
+		IP:        payload.IP,
+		UserAgent: payload.UserAgent,
+	})
+	if result.Error != nil {
+		return nil, result
+	}
+
+	user, ok := result.Data.(*model.User)
+	if !ok {
+		return nil, result
+	}
+
+	return user, utils.Result{}
+}
+
+// validateAuthorize checks an authorization request. Problems with the client
+// or redirect URI are returned as errors for the user agent; everything else
+// is reported back to the client through a redirect, as RFC 6749 §4.1.2.1 requires.
+func (u *usecase) validateAuthorize(payload *dto.AuthorizeRequest) (*authorizeRequest, *dto.AuthorizeRedirect, interface{}) {
+	client, err := u.repository.GetClientByClientID(payload.ClientID)
+	if err != nil {
+		return nil, nil, httpError.NewInternalServerError(err.Error())
+	}
+
+	if client == nil {
+		return nil, nil, httpError.NewBadRequest("unknown client_id")
+	}
+
+	redirectURI := payload.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+
+	if !contains(client.RedirectURIs, redirectURI) {
+		return nil, nil, httpError.NewBadRequest("redirect_uri is not registered for this client")
+	}
+
+	if payload.ResponseType != "code" {
+		return nil, errorRedirect(redirectURI, payload.State, "unsupported_response_type", "only the code response type is supported"), nil
+	}
+
+	if !contains(client.GrantTypes, GrantAuthorizationCode) {
+		return nil, errorRedirect(redirectURI, payload.State, "unauthorized_client", "client may not use the authorization code grant"), nil
+	}
+
+	if payload.CodeChallenge == "" {
+		return nil, errorRedirect(redirectURI, payload.State, "invalid_request", "code_challenge is required"), nil
+	}
+
+	if payload.CodeChallengeMethod != "S256" {
+		return nil, errorRedirect(redirectURI, payload.State, "invalid_request", "code_challenge_method must be S256"), nil
+	}
+
+	scopes := parseScope(payload.Scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+
+	if !isSubset(scopes, client.Scopes) {
+		return nil, errorRedirect(redirectURI, payload.State, "invalid_scope", "requested scope is not allowed for this client"), nil
+	}
+
+	return &authorizeRequest{client, redirectURI, scopes}, nil, nil
+}
+
+func (u *usecase) Token(ctx context.Context, payload *dto.OAuthTokenRequest) (result utils.Result) {
+	client, oauthErr := u.authenticateClient(payload.ClientID, payload.ClientSecret)
+	if oauthErr != nil {
+		result.Error = *oauthErr
+		return result
+	}
+
+	if payload.GrantType == "" {
+		result.Error = invalidRequest("grant_type is required")
+		return result
+	}
+
+	if !contains(client.GrantTypes, payload.GrantType) {
+		result.Error = unauthorizedClient("client may not use the " + payload.GrantType + " grant")
+		return result
+	}
+
+	switch payload.GrantType {
+	case GrantAuthorizationCode:
+		return u.exchangeCode(ctx, client, payload)
+	case GrantRefreshToken:
+		return u.exchangeRefreshToken(ctx, client, payload)
+	case GrantClientCredentials:
+		return u.exchangeClientCredentials(ctx, client, payload)
+	default:
+		result.Error = unsupportedGrantType(payload.GrantType + " is not supported")
+		return result
+	}
+}
+
+// Revoke implements RFC 7009. Tokens that are unknown, expired or issued to
+// another client are ignored so the response does not reveal anything about them.
+func (u *usecase) Revoke(ctx context.Context, payload *dto.OAuthRevokeRequest) (result utils.Result) {
+	client, oauthErr := u.authenticateClient(payload.ClientID, payload.ClientSecret)
+	if oauthErr != nil {
+		result.Error = *oauthErr
+		return result
+	}
+
+	if payload.Token == "" {
+		result.Error = invalidRequest("token is required")
+		return result
+	}
+
+	// token_type_hint is not needed, access tokens are JWTs and are told
+	// apart from the opaque refresh tokens by parsing them
+	isAccessToken, err := u.revokeAccessToken(ctx, client, payload.Token)
+	if err != nil {
+		result.Error = serverError(err)
+		return result
+	}
+
+	if !isAccessToken {
+		if err := u.refreshUsecase.Revoke(ctx, payload.Token, client.ClientID); err != nil {
+			result.Error = serverError(err)
+			return result
+		}
+	}
+
+	return result
+}
+
+// revokeAccessToken denylists raw when it is a valid access token of the
+// client. It reports false when raw is not an access token at all.
+func (u *usecase) revokeAccessToken(ctx context.Context, client *model.OAuthClient, raw string) (bool, error) {
+	claims, err := u.tokenManager.Parse(raw)
+	if err != nil {
+		return false, nil
+	}
+
+	if claims.ClientID != client.ClientID || claims.ID == "" {
+		return true, nil
+	}
+
+	return true, u.denylist.Add(ctx, claims.ID, claims.ExpiresAt.Time)
+}
+
+// Introspect implements RFC 7662 for confidential clients. A token is reported
+// active only while it is unexpired, not revoked and its user is not deleted.
+func (u *usecase) Introspect(ctx context.Context, payload *dto.OAuthIntrospectRequest) (result utils.Result) {
+	client, oauthErr := u.authenticateClient(payload.ClientID, payload.ClientSecret)
+	if oauthErr != nil {
+		result.Error = *oauthErr
+		return result
+	}
+
+	if !client.IsConfidential {
+		result.Error = unauthorizedClient("public clients may not introspect tokens")
+		return result
+	}
+
+	if payload.Token == "" {
+		result.Error = invalidRequest("token is required")
+		return result
+	}
+
+	response, err := u.introspectAccessToken(ctx, payload.Token)
+	if err != nil {
+		result.Error = serverError(err)
+		return result
+	}
+
+	if response == nil {
+		response, err = u.introspectRefreshToken(ctx, payload.Token)
+		if err != nil {
+			result.Error = serverError(err)
+			return result
+		}
+	}
+
+	if response == nil {
+		response = &dto.OAuthIntrospectResponse{Active: false}
+	}
+
+	result.Data = response
+
+	return result
+}
+
+// introspectAccessToken returns nil when raw is not a valid access token.
+func (u *usecase) introspectAccessToken(ctx context.Context, raw string) (*dto.OAuthIntrospectResponse, error) {
+	claims, err := u.tokenManager.Parse(raw)
+	if err != nil {
+		return nil, nil
+	}
+
+	inactive := &dto.OAuthIntrospectResponse{Active: false}
+
+	if claims.ID != "" {
+		revoked, err := u.denylist.Contains(ctx, claims.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if revoked {
+			return inactive, nil
+		}
+	}
+
+	response := &dto.OAuthIntrospectResponse{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientID:  claims.ClientID,
+		TokenType: "Bearer",
+		Exp:       claims.ExpiresAt.Unix(),
+		Sub:       claims.Subject,
+		Aud:       claims.Audience,
+		Iss:       claims.Issuer,
+		Jti:       claims.ID,
+	}
+
+	if claims.IssuedAt != nil {
+		response.Iat = claims.IssuedAt.Unix()
+	}
+
+	// client_credentials tokens are issued to the client itself
+	if claims.ClientID != "" && claims.Subject == claims.ClientID {
+		owner, err := u.repository.GetClientByClientID(claims.ClientID)
+		if err != nil {
+			return nil, err
+		}
+
+		if owner == nil {
+			return inactive, nil
+		}
+
+		return response, nil
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return inactive, nil
+	}
+
+	user, err := u.activeUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return inactive, nil
+	}
+
+	response.Username = user.Email
+
+	return response, nil
+}
+
+// introspectRefreshToken returns nil when raw is not a usable refresh token.
+func (u *usecase) introspectRefreshToken(ctx context.Context, raw string) (*dto.OAuthIntrospectResponse, error) {
+	refreshToken, err := u.refreshUsecase.Lookup(ctx, raw)
+	if err != nil || refreshToken == nil {
+		return nil, err
+	}
+
+	user, err := u.activeUser(refreshToken.UserID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	response := &dto.OAuthIntrospectResponse{
+		Active:    true,
+		Username:  user.Email,
+		TokenType: "refresh_token",
+		Exp:       refreshToken.ExpiresAt.Unix(),
+		Iat:       refreshToken.CreatedAt.Unix(),
+		Sub:       token.UserSubject(user.ID),
+		Iss:       u.tokenManager.Issuer(),
+	}
+
+	if refreshToken.Scope != nil {
+		response.Scope = *refreshToken.Scope
+	}
+
+	if refreshToken.ClientID != nil {
+		response.ClientID = *refreshToken.ClientID
+	}
+
+	return response, nil
+}
+
+// activeUser returns the user unless it is missing, soft-deleted or not active.
+func (u *usecase) activeUser(id int64) (*model.User, error) {
+	user, err := u.authRepository.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil || user.DeletedAt != nil || user.Status != model.UserStatusActive {
+		return nil, nil
+	}
+
+	return user, nil
+}
+
+func (u *usecase) authenticateClient(clientID, clientSecret string) (*model.OAuthClient, *Error) {
+	if clientID == "" {
+		err := invalidClient("client authentication is required")
+		return nil, &err
+	}
+
+	client, err := u.repository.GetClientByClientID(clientID)
+	if err != nil {
+		serverErr := serverError(err)
+		return nil, &serverErr
+	}
+
+	if client == nil {
+		err := invalidClient("unknown client")
+		return nil, &err
+	}
+
+	if client.IsConfidential {
+		if client.ClientSecretHash == nil ||
+			subtle.ConstantTimeCompare([]byte(utils.HashToken(clientSecret)), []byte(*client.ClientSecretHash)) != 1 {
+			err := invalidClient("invalid client credentials")
+			return nil, &err
+		}
+	}
+
+	return client, nil
+}
+
+func (u *usecase) exchangeCode(ctx context.Context, client *model.OAuthClient, payload *dto.OAuthTokenRequest) (result utils.Result) {
+	if payload.Code == "" || payload.CodeVerifier == "" {
+		result.Error = invalidRequest("code and code_verifier are required")
+		return result
+	}
+
+	code, err := u.repository.GetCodeByHash(utils.HashToken(payload.Code))
+	if err != nil {
+		result.Error = serverError(err)
+		return result
+	}
+
+	if code == nil || code.ClientID != client.ClientID {
+		result.Error = invalidGrant("invalid authorization code")
+		return result
+	}
+
+	redeemed, err := u.repository.MarkCodeUsed(code.ID)
+	if err != nil {
+		result.Error = serverError(err)
+		return result
+	}
+
+	if !redeemed {
+		result.Error = invalidGrant("authorization code has already been used")
+		return result
+	}
+
+	if time.Now().After(code.ExpiresAt) {
+		result.Error = invalidGrant("authorization code expired")
+		return result
+	}
+
+	// the redirect_uri is only required when /authorize received one, a code
+	// issued for the client's sole registered uri can be redeemed without it
+	if (code.RedirectURIExplicit || payload.RedirectURI != "") && payload.RedirectURI != code.RedirectURI {
+		result.Error = invalidGrant("redirect_uri does not match the authorization request")
+		return result
+	}
+
+	if !verifyCodeChallenge(payload.CodeVerifier, code.CodeChallenge) {
+		result.Error = invalidGrant("code_verifier does not match code_challenge")
+		return result
+	}
+
+	user, err := u.authRepository.GetByID(code.UserID)
+	if err != nil {
+		result.Error = serverError(err)
+		return result
+	}
+
+	if user == nil || user.DeletedAt != nil {
+		result.Error = invalidGrant("resource owner no longer exists")
+		return result
+	}
+
+	if user.Status != model.UserStatusActive {
+		result.Error = invalidGrant("resource owner account is " + user.Status)
+		return result
+	}
+
+	response, err := u.userTokenResponse(user, client.ClientID, code.Scope)
+	if err != nil {
+		result.Error = serverError(err)
+		return result
+	}
+
+	if contains(client.GrantTypes, GrantRefreshToken) {
+		response.RefreshToken, err = u.refreshUsecase.IssueForClient(ctx, user.ID, client.ClientID, code.Scope)
+		if err != nil {
+			result.Error = serverError(err)
+			return result
+		}
+	}
+
+	if oidc.HasScope(code.Scope, oidc.ScopeOpenID) {
+		response.IDToken, err = u.idToken(user, code)
+		if err != nil {
+			result.Error = serverError(err)
+			return result
+		}
+	}
+
+	result.Data = response
+
+	return result
+}
+
+func (u *usecase) exchangeRefreshToken(ctx context.Context, client *model.OAuthClient, payload *dto.OAuthTokenRequest) (result utils.Result) {
+	if payload.RefreshToken == "" {
+		result.Error = invalidRequest("refresh_token is required")
+		return result
+	}
+
+	previous, refreshToken, err := u.refreshUsecase.Rotate(ctx, payload.RefreshToken, client.ClientID)
+	if errors.Is(err, refresh.ErrInvalidToken) || errors.Is(err, refresh.ErrExpiredToken) || errors.Is(err, refresh.ErrTokenReused) {
+		result.Error = invalidGrant(err.Error())
+		return result
+	}
+	if err != nil {
+		result.Error = serverError(err)
+		return result
+	}
+
+	scope := ""
+	if previous.Scope != nil {
+		scope = *previous.Scope
+	}
+
+	if payload.Scope != "" {
+		if !isSubset(parseScope(payload.Scope), parseScope(scope)) {
+			result.Error = invalidScope("requested scope exceeds the original grant")
+			return result
+		}
+		scope = payload.Scope
+	}
+
+	user, err := u.authRepository.GetByID(previous.UserID)
+	if err != nil {
+		result.Error = serverError(err)
+		return result
+	}
+
+	if user == nil || user.DeletedAt != nil {
+		result.Error = invalidGrant("resource owner no longer exists")
+		return result
+	}
+
+	if user.Status != model.UserStatusActive {
+		result.Error = invalidGrant("resource owner account is " + user.Status)
+		return result
+	}
+
+	response, err := u.userTokenResponse(user, client.ClientID, scope)
+	if err != nil {
+		result.Error = serverError(err)
+		return result
+	}
+
+	response.RefreshToken = refreshToken
+	result.Data = response
+
+	return result
+}
+
+func (u *usecase) exchangeClientCredentials(ctx context.Context, client *model.OAuthClient, payload *dto.OAuthTokenRequest) (result utils.Result) {
+	if !client.IsConfidential {
+		result.Error = unauthorizedClient("public clients may not use the client_credentials grant")
+		return result
+	}
+
+	scopes := parseScope(payload.Scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+
+	if !isSubset(scopes, client.Scopes) {
+		result.Error = invalidScope("requested scope is not allowed for this client")
+		return result
+	}
+
+	claims := token.Claims{
+		Scope:    joinScope(scopes),
+		ClientID: client.ClientID,
+	}
+	claims.Subject = client.ClientID
+
+	accessToken, expiresAt, err := u.tokenManager.IssueAccessToken(claims)
+	if err != nil {
+		result.Error = serverError(err)
+		return result
+	}
+
+	result.Data = &dto.OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(time.Until(expiresAt).Seconds()),
+		Scope:       claims.Scope,
+	}
+
+	return result
+}
+
+func (u *usecase) userTokenResponse(user *model.User, clientID, scope string) (*dto.OAuthTokenResponse, error) {
+	roles, err := u.rbacRepository.GetUserRoles(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := token.Claims{
+		Email:    user.Email,
+		Roles:    roles,
+		Scope:    scope,
+		ClientID: clientID,
+	}
+	claims.Subject = token.UserSubject(user.ID)
+
+	accessToken, expiresAt, err := u.tokenManager.IssueAccessToken(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(time.Until(expiresAt).Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+func (u *usecase) idToken(user *model.User, code *model.AuthorizationCode) (string, error) {
+	standard := oidc.StandardClaims(user, code.Scope)
+
+	claims := token.IDTokenClaims{
+		Email:         standard.Email,
+		EmailVerified: standard.EmailVerified,
+		AuthTime:      jwt.NewNumericDate(code.AuthTime),
+	}
+	claims.Subject = standard.Subject
+
+	if code.Nonce != nil {
+		claims.Nonce = *code.Nonce
+	}
+
+	return u.tokenManager.IssueIDToken(claims, code.ClientID)
+}
+
+func (u *usecase) ListClients(ctx context.Context) (result utils.Result) {
+	clients, err := u.repository.GetClients()
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	result.Data = clients
+
+	return result
+}
+
+func (u *usecase) GetClient(ctx context.Context, clientID string) (result utils.Result) {
+	client, err := u.repository.GetClientByClientID(clientID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if client == nil {
+		result.Error = httpError.NewNotFound("client not found")
+		return result
+	}
+
+	result.Data = client
+
+	return result
+}
+
+func (u *usecase) CreateClient(ctx context.Context, payload *dto.CreateClientRequest) (result utils.Result) {
+	if payload.Name == "" {
+		result.Error = httpError.NewBadRequest("name is required")
+		return result
+	}
+
+	if len(payload.GrantTypes) == 0 {
+		payload.GrantTypes = []string{GrantAuthorizationCode, GrantRefreshToken}
+	}
+
+	for _, grantType := range payload.GrantTypes {
+		if !contains([]string{GrantAuthorizationCode, GrantRefreshToken, GrantClientCredentials}, grantType) {
+			result.Error = httpError.NewBadRequest("unsupported grant type " + grantType)
+			return result
+		}
+	}
+
+	if contains(payload.GrantTypes, GrantAuthorizationCode) && len(payload.RedirectURIs) == 0 {
+		result.Error = httpError.NewBadRequest("redirect_uris are required for the authorization code grant")
+		return result
+	}
+
+	for _, redirectURI := range payload.RedirectURIs {
+		parsed, err := url.Parse(redirectURI)
+		if err != nil || !parsed.IsAbs() || parsed.Fragment != "" {
+			result.Error = httpError.NewBadRequest("invalid redirect uri " + redirectURI)
+			return result
+		}
+	}
+
+	if !payload.IsConfidential && contains(payload.GrantTypes, GrantClientCredentials) {
+		result.Error = httpError.NewBadRequest("public clients may not use the client_credentials grant")
+		return result
+	}
+
+	clientID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	client := &model.OAuthClient{
+		ClientID:       clientID,
+		Name:           payload.Name,
+		RedirectURIs:   payload.RedirectURIs,
+		GrantTypes:     payload.GrantTypes,
+		Scopes:         payload.Scopes,
+		IsConfidential: payload.IsConfidential,
+	}
+
+	if client.RedirectURIs == nil {
+		client.RedirectURIs = []string{}
+	}
+
+	if client.Scopes == nil {
+		client.Scopes = []string{}
+	}
+
+	var secret string
+	if client.IsConfidential {
+		secret, err = utils.GenerateRandomToken(32)
+		if err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+
+		secretHash := utils.HashToken(secret)
+		client.ClientSecretHash = &secretHash
+	}
+
+	err = u.repository.InsertClient(client)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	// the plain secret is only ever returned here
+	result.Data = &dto.CreateClientResponse{
+		OAuthClient:  *client,
+		ClientSecret: secret,
+	}
+
+	return result
+}
+
+func (u *usecase) DeleteClient(ctx context.Context, clientID string) (result utils.Result) {
+	client, err := u.repository.GetClientByClientID(clientID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if client == nil {
+		result.Error = httpError.NewNotFound("client not found")
+		return result
+	}
+
+	err = u.repository.SoftDeleteClient(clientID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	return result
+}
+
+func verifyCodeChallenge(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+func errorRedirect(redirectURI, state, code, description string) *dto.AuthorizeRedirect {
+	query := url.Values{"error": {code}, "error_description": {description}}
+	if state != "" {
+		query.Set("state", state)
+	}
+
+	return &dto.AuthorizeRedirect{URL: withQuery(redirectURI, query)}
+}
+
+func withQuery(rawURL string, values url.Values) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	query := parsed.Query()
+	for key, value := range values {
+		query[key] = value
+	}
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String()
+}
@@ -0,0 +1,43 @@
+package oauth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	// RFC 7636 Appendix B
+	const (
+		verifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+		challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	)
+
+	tests := []struct {
+		name      string
+		verifier  string
+		challenge string
+		want      bool
+	}{
+		{"rfc 7636 example", verifier, challenge, true},
+		{"wrong verifier", verifier[:42] + "Y", challenge, false},
+		{"wrong challenge", verifier, challenge[:42] + "N", false},
+		{"plain method", verifier, verifier, false},
+		{"padded challenge", verifier, challenge + "=", false},
+		{"standard base64 challenge", verifier, strings.NewReplacer("-", "+", "_", "/").Replace(challenge), false},
+		{"empty verifier", "", challenge, false},
+		{"empty challenge", verifier, "", false},
+		// RFC 7636 section 4.1 allows 43 to 128 characters
+		{"verifier too short", verifier[:42], challenge, false},
+		{"verifier too long", strings.Repeat("a", 129), challenge, false},
+		{"shortest verifier", strings.Repeat("a", 43), "ZtNPunH49FD35FWYhT5Tv8I7vRKQJ8uxMaL0_9eHjNA", true},
+		{"longest verifier", strings.Repeat("a", 128), "aDbPE7rEAOkQUHHNavRwhN-srU5eMCyUv-0k4BOvtz4", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyCodeChallenge(tt.verifier, tt.challenge); got != tt.want {
+				t.Fatalf("verifyCodeChallenge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package oidc
+
+import (
+	"strings"
+
+	"github.com/helyus1412/auth-service/dto"
+	"github.com/helyus1412/auth-service/model"
+	"github.com/helyus1412/auth-service/pkg/token"
+)
+
+const (
+	ScopeOpenID  = "openid"
+	ScopeEmail   = "email"
+	ScopeProfile = "profile"
+)
+
+// HasScope reports whether a space-delimited scope string contains scope.
+func HasScope(scope string, want string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == want {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StandardClaims maps a user onto the OpenID Connect standard claims released
+// for the granted scope.
+func StandardClaims(user *model.User, scope string) *dto.UserInfoResponse {
+	claims := &dto.UserInfoResponse{
+		Subject: token.UserSubject(user.ID),
+	}
+
+	if HasScope(scope, ScopeEmail) {
+		verified := user.EmailVerifiedAt != nil
+		claims.Email = user.Email
+		claims.EmailVerified = &verified
+	}
+
+	if HasScope(scope, ScopeProfile) {
+		updatedAt := user.CreatedAt.Unix()
+		if user.UpdatedAt != nil {
+			updatedAt = user.UpdatedAt.Unix()
+		}
+		claims.UpdatedAt = &updatedAt
+	}
+
+	return claims
+}
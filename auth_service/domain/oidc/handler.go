@@ -0,0 +1,64 @@
+package oidc
+
+import (
+	"net/http"
+
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Handler serves the OpenID Connect endpoints. Responses are plain JSON rather
+// than the usual envelope so that off-the-shelf OIDC clients can consume them.
+type Handler interface {
+	Discovery(c echo.Context) error
+	JWKS(c echo.Context) error
+	UserInfo(c echo.Context) error
+}
+
+type handler struct {
+	usecase Usecase
+	tc      trace.Tracer
+}
+
+func NewHandler(usecase Usecase, tc trace.Tracer) Handler {
+	return &handler{usecase, tc}
+}
+
+func (h *handler) Discovery(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Discovery")
+	defer span.End()
+
+	result := h.usecase.Discovery(ctx)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return c.JSON(http.StatusOK, result.Data)
+}
+
+func (h *handler) JWKS(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.JWKS")
+	defer span.End()
+
+	result := h.usecase.JWKS(ctx)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=300")
+
+	return c.JSON(http.StatusOK, result.Data)
+}
+
+func (h *handler) UserInfo(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.UserInfo")
+	defer span.End()
+
+	result := h.usecase.UserInfo(ctx)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return c.JSON(http.StatusOK, result.Data)
+}
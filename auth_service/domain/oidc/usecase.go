@@ -0,0 +1,98 @@
+package oidc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/helyus1412/auth-service/domain/auth"
+	"github.com/helyus1412/auth-service/dto"
+	"github.com/helyus1412/auth-service/model"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/principal"
+	"github.com/helyus1412/auth-service/pkg/token"
+	"github.com/helyus1412/auth-service/pkg/utils"
+)
+
+type Usecase interface {
+	Discovery(context.Context) utils.Result
+	JWKS(context.Context) utils.Result
+	UserInfo(context.Context) utils.Result
+}
+
+type usecase struct {
+	authRepository auth.Repository
+	tokenManager   *token.Manager
+}
+
+func NewUsecase(authRepository auth.Repository, tokenManager *token.Manager) Usecase {
+	return &usecase{authRepository, tokenManager}
+}
+
+func (u *usecase) Discovery(ctx context.Context) (result utils.Result) {
+	issuer := strings.TrimSuffix(u.tokenManager.Issuer(), "/")
+
+	result.Data = &dto.DiscoveryResponse{
+		Issuer:                            u.tokenManager.Issuer(),
+		AuthorizationEndpoint:             issuer + "/oauth/authorize",
+		TokenEndpoint:                     issuer + "/oauth/token",
+		RevocationEndpoint:                issuer + "/oauth/revoke",
+		IntrospectionEndpoint:             issuer + "/oauth/introspect",
+		UserInfoEndpoint:                  issuer + "/userinfo",
+		JWKSURI:                           issuer + "/.well-known/jwks.json",
+		ScopesSupported:                   []string{ScopeOpenID, ScopeEmail, ScopeProfile},
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code", "refresh_token", "client_credentials"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{u.tokenManager.Algorithm()},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "email", "email_verified", "updated_at"},
+	}
+
+	return result
+}
+
+func (u *usecase) JWKS(ctx context.Context) (result utils.Result) {
+	set, err := u.tokenManager.JWKSet()
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	result.Data = set
+
+	return result
+}
+
+func (u *usecase) UserInfo(ctx context.Context) (result utils.Result) {
+	caller, ok := principal.FromContext(ctx)
+	if !ok {
+		result.Error = httpError.NewUnauthorized("")
+		return result
+	}
+
+	if !HasScope(caller.Scope, ScopeOpenID) {
+		result.Error = httpError.NewForbidden("access token lacks the openid scope")
+		return result
+	}
+
+	user, err := u.authRepository.GetByID(caller.UserID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if user == nil || user.DeletedAt != nil {
+		result.Error = httpError.NewUnauthorized("user not found")
+		return result
+	}
+
+	if user.Status != model.UserStatusActive {
+		result.Error = httpError.NewUnauthorized("user is " + user.Status)
+		return result
+	}
+
+	result.Data = StandardClaims(user, caller.Scope)
+
+	return result
+}
@@ -0,0 +1,89 @@
+package passkey
+
+import (
+	"net/http"
+
+	"github.com/helyus1412/auth-service/dto"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Handler interface {
+	BeginRegistration(c echo.Context) error
+	FinishRegistration(c echo.Context) error
+	ListCredentials(c echo.Context) error
+	DeleteCredential(c echo.Context) error
+}
+
+type handler struct {
+	usecase Usecase
+	tc      trace.Tracer
+}
+
+func NewHandler(usecase Usecase, tc trace.Tracer) Handler {
+	return &handler{usecase, tc}
+}
+
+func (h *handler) BeginRegistration(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.BeginRegistration")
+	defer span.End()
+
+	result := h.usecase.BeginRegistration(ctx)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Passkey Registration Options", http.StatusOK, c)
+}
+
+func (h *handler) FinishRegistration(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.FinishRegistration")
+	defer span.End()
+
+	var payload dto.PasskeyFinishRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.FinishRegistration(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Register Passkey", http.StatusCreated, c)
+}
+
+func (h *handler) ListCredentials(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.ListCredentials")
+	defer span.End()
+
+	result := h.usecase.ListCredentials(ctx)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "List Passkey", http.StatusOK, c)
+}
+
+func (h *handler) DeleteCredential(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.DeleteCredential")
+	defer span.End()
+
+	var payload dto.PasskeyIDRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.DeleteCredential(ctx, payload.ID)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Delete Passkey", http.StatusOK, c)
+}
@@ -0,0 +1,151 @@
+package passkey
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/helyus1412/auth-service/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	GetCredentials(userID int64) ([]model.WebAuthnCredential, error)
+	CountCredentials(userID int64) (int, error)
+	InsertCredential(*model.WebAuthnCredential) error
+	UpdateCredentialUsage(*model.WebAuthnCredential) error
+	DeleteCredential(userID int64, id int64) (bool, error)
+	InsertSession(*model.WebAuthnSession) error
+	TakeSession(id string, ceremony string) (*model.WebAuthnSession, error)
+}
+
+type repository struct {
+	db     *sqlx.DB
+	schema string
+}
+
+func NewRepository(db *sqlx.DB, schema string) Repository {
+	if schema == "" {
+		schema = "public"
+	}
+
+	return &repository{db, schema}
+}
+
+func (r *repository) GetCredentials(userID int64) ([]model.WebAuthnCredential, error) {
+	res := []model.WebAuthnCredential{}
+
+	query, err := r.db.Preparex(fmt.Sprintf(`SELECT * from %s.webauthn_credentials where user_id = $1 order by id`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Select(&res, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *repository) CountCredentials(userID int64) (int, error) {
+	var count int
+
+	query, err := r.db.Preparex(fmt.Sprintf(`SELECT count(*) from %s.webauthn_credentials where user_id = $1`, r.schema))
+	if err != nil {
+		return 0, err
+	}
+
+	err = query.Get(&count, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *repository) InsertCredential(credential *model.WebAuthnCredential) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`INSERT INTO %s.webauthn_credentials (user_id, credential_id, public_key,
+		attestation_type, transports, aaguid, sign_count, backup_eligible, backup_state, name)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	if credential.Transports == nil {
+		credential.Transports = []string{}
+	}
+
+	return queryPrep.QueryRowx(credential.UserID, credential.CredentialID, credential.PublicKey, credential.AttestationType,
+		credential.Transports, credential.AAGUID, credential.SignCount, credential.BackupEligible, credential.BackupState,
+		credential.Name).Scan(&credential.ID, &credential.CreatedAt)
+}
+
+// UpdateCredentialUsage stores the state reported by the authenticator on a successful assertion.
+func (r *repository) UpdateCredentialUsage(credential *model.WebAuthnCredential) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.webauthn_credentials set sign_count = $1, clone_warning = $2,
+		backup_state = $3, last_used_at = NOW() where id = $4`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(credential.SignCount, credential.CloneWarning, credential.BackupState, credential.ID)
+
+	return err
+}
+
+func (r *repository) DeleteCredential(userID int64, id int64) (bool, error) {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`delete from %s.webauthn_credentials where id = $1 and user_id = $2`, r.schema))
+	if err != nil {
+		return false, err
+	}
+
+	res, err := queryPrep.Exec(id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *repository) InsertSession(session *model.WebAuthnSession) error {
+	// abandoned ceremonies are swept here rather than by a separate job
+	if _, err := r.db.Exec(fmt.Sprintf(`delete from %s.webauthn_sessions where expires_at < NOW()`, r.schema)); err != nil {
+		return err
+	}
+
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`INSERT INTO %s.webauthn_sessions (id, user_id, ceremony, data, expires_at)
+		VALUES ($1, $2, $3, $4, $5)`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(session.ID, session.UserID, session.Ceremony, session.Data, session.ExpiresAt)
+
+	return err
+}
+
+// TakeSession deletes and returns a ceremony session so that it can only be finished once.
+func (r *repository) TakeSession(id string, ceremony string) (*model.WebAuthnSession, error) {
+	var res model.WebAuthnSession
+
+	query, err := r.db.Preparex(fmt.Sprintf(`delete from %s.webauthn_sessions where id = $1 and ceremony = $2 RETURNING *`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Get(&res, id, ceremony)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,497 @@
+package passkey
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/helyus1412/auth-service/dto"
+	"github.com/helyus1412/auth-service/model"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"github.com/helyus1412/auth-service/pkg/principal"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+const (
+	ceremonyRegistration = "registration"
+	ceremonyLogin        = "login"
+
+	defaultCredentialName = "Passkey"
+)
+
+var (
+	ErrInvalidSession   = errors.New("passkey session is invalid or expired")
+	ErrInvalidAssertion = errors.New("passkey assertion could not be verified")
+)
+
+// Assertion is the outcome of a successful login ceremony.
+type Assertion struct {
+	UserID int64
+	// UserVerified is set when the authenticator verified the user with a PIN or biometric.
+	UserVerified bool
+}
+
+type Usecase interface {
+	BeginRegistration(context.Context) utils.Result
+	FinishRegistration(context.Context, *dto.PasskeyFinishRequest) utils.Result
+	ListCredentials(context.Context) utils.Result
+	DeleteCredential(context.Context, int64) utils.Result
+	// HasCredentials reports whether the user registered at least one passkey.
+	HasCredentials(ctx context.Context, userID int64) (bool, error)
+	// BeginLogin starts an assertion ceremony. A zero userID starts a
+	// passwordless login where the authenticator picks the account.
+	BeginLogin(ctx context.Context, userID int64) (*dto.PasskeyOptionsResponse, error)
+	// FinishLogin verifies the assertion for a session started by BeginLogin
+	// with the same userID.
+	FinishLogin(ctx context.Context, sessionID string, credential []byte, userID int64) (*Assertion, error)
+}
+
+type Config struct {
+	RPID          string
+	RPDisplayName string
+	RPOrigins     []string
+	SessionTTL    time.Duration
+}
+
+type usecase struct {
+	repository Repository
+	webAuthn   *webauthn.WebAuthn
+	sessionTTL time.Duration
+	logger     *logger.Logger
+}
+
+func NewUsecase(repository Repository, config Config, logger *logger.Logger) (Usecase, error) {
+	timeout := webauthn.TimeoutConfig{
+		Enforce:    true,
+		Timeout:    config.SessionTTL,
+		TimeoutUVD: config.SessionTTL,
+	}
+
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:                  config.RPID,
+		RPDisplayName:         config.RPDisplayName,
+		RPOrigins:             config.RPOrigins,
+		AttestationPreference: protocol.PreferNoAttestation,
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        timeout,
+			Registration: timeout,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &usecase{repository, webAuthn, config.SessionTTL, logger}, nil
+}
+
+func (u *usecase) BeginRegistration(ctx context.Context) (result utils.Result) {
+	caller, ok := principal.FromContext(ctx)
+	if !ok {
+		result.Error = httpError.NewUnauthorized("")
+		return result
+	}
+
+	user, err := u.loadUser(caller.UserID, caller.Email)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	creation, session, err := u.webAuthn.BeginRegistration(user,
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+		webauthn.WithExclusions(webauthn.Credentials(user.WebAuthnCredentials()).CredentialDescriptors()),
+	)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	sessionID, err := u.saveSession(caller.UserID, ceremonyRegistration, session)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	result.Data = &dto.PasskeyOptionsResponse{SessionID: sessionID, Options: creation}
+
+	return result
+}
+
+func (u *usecase) FinishRegistration(ctx context.Context, payload *dto.PasskeyFinishRequest) (result utils.Result) {
+	caller, ok := principal.FromContext(ctx)
+	if !ok {
+		result.Error = httpError.NewUnauthorized("")
+		return result
+	}
+
+	session, err := u.takeSession(payload.SessionID, ceremonyRegistration, caller.UserID)
+	if errors.Is(err, ErrInvalidSession) {
+		result.Error = httpError.NewBadRequest(err.Error())
+		return result
+	}
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(payload.Credential)
+	if err != nil {
+		result.Error = httpError.NewBadRequest("malformed credential")
+		return result
+	}
+
+	user, err := u.loadUser(caller.UserID, caller.Email)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	created, err := u.webAuthn.CreateCredential(user, *session, parsed)
+	if err != nil {
+		result.Error = httpError.NewBadRequest("credential could not be verified")
+		return result
+	}
+
+	name := strings.TrimSpace(payload.Name)
+	if name == "" {
+		name = defaultCredentialName
+	}
+
+	credential := &model.WebAuthnCredential{
+		UserID:          caller.UserID,
+		CredentialID:    created.ID,
+		PublicKey:       created.PublicKey,
+		AttestationType: created.AttestationType,
+		Transports:      transportsToStrings(created.Transport),
+		AAGUID:          created.Authenticator.AAGUID,
+		SignCount:       int64(created.Authenticator.SignCount),
+		BackupEligible:  created.Flags.BackupEligible,
+		BackupState:     created.Flags.BackupState,
+		Name:            name,
+	}
+
+	err = u.repository.InsertCredential(credential)
+	if isUniqueViolation(err) {
+		result.Error = httpError.NewConflict("passkey is already registered")
+		return result
+	}
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	u.logger.Info(ctx, "passkey.FinishRegistration", "Registered", "passkey registered",
+		zap.Int64("user_id", caller.UserID),
+		zap.Int64("credential_id", credential.ID),
+	)
+
+	result.Data = credential
+
+	return result
+}
+
+func (u *usecase) ListCredentials(ctx context.Context) (result utils.Result) {
+	caller, ok := principal.FromContext(ctx)
+	if !ok {
+		result.Error = httpError.NewUnauthorized("")
+		return result
+	}
+
+	credentials, err := u.repository.GetCredentials(caller.UserID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	result.Data = credentials
+
+	return result
+}
+
+func (u *usecase) DeleteCredential(ctx context.Context, id int64) (result utils.Result) {
+	caller, ok := principal.FromContext(ctx)
+	if !ok {
+		result.Error = httpError.NewUnauthorized("")
+		return result
+	}
+
+	deleted, err := u.repository.DeleteCredential(caller.UserID, id)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if !deleted {
+		result.Error = httpError.NewNotFound("passkey not found")
+		return result
+	}
+
+	u.logger.Warn(ctx, "passkey.DeleteCredential", "Deleted", "passkey removed",
+		zap.Int64("user_id", caller.UserID),
+		zap.Int64("credential_id", id),
+	)
+
+	return result
+}
+
+func (u *usecase) HasCredentials(ctx context.Context, userID int64) (bool, error) {
+	count, err := u.repository.CountCredentials(userID)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (u *usecase) BeginLogin(ctx context.Context, userID int64) (*dto.PasskeyOptionsResponse, error) {
+	var (
+		assertion *protocol.CredentialAssertion
+		session   *webauthn.SessionData
+		err       error
+	)
+
+	if userID == 0 {
+		// passwordless sign-in stands on its own, so the authenticator must verify the user
+		assertion, session, err = u.webAuthn.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	} else {
+		var user *webAuthnUser
+		user, err = u.loadUser(userID, "")
+		if err != nil {
+			return nil, err
+		}
+
+		if len(user.credentials) == 0 {
+			return nil, ErrInvalidSession
+		}
+
+		assertion, session, err = u.webAuthn.BeginLogin(user)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := u.saveSession(userID, ceremonyLogin, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PasskeyOptionsResponse{SessionID: sessionID, Options: assertion}, nil
+}
+
+func (u *usecase) FinishLogin(ctx context.Context, sessionID string, credential []byte, userID int64) (*Assertion, error) {
+	session, err := u.takeSession(sessionID, ceremonyLogin, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(credential)
+	if err != nil {
+		return nil, ErrInvalidAssertion
+	}
+
+	var (
+		user      *webAuthnUser
+		validated *webauthn.Credential
+	)
+
+	if userID == 0 {
+		handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+			id, err := strconv.ParseInt(string(userHandle), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+
+			user, err = u.loadUser(id, "")
+			return user, err
+		}
+
+		_, validated, err = u.webAuthn.ValidatePasskeyLogin(handler, *session, parsed)
+	} else {
+		user, err = u.loadUser(userID, "")
+		if err != nil {
+			return nil, err
+		}
+
+		validated, err = u.webAuthn.ValidateLogin(user, *session, parsed)
+	}
+	if err != nil {
+		u.logger.Warn(ctx, "passkey.FinishLogin", "Validate", "passkey assertion rejected", zap.Error(err))
+		return nil, ErrInvalidAssertion
+	}
+
+	stored := user.find(validated.ID)
+	if stored == nil {
+		return nil, ErrInvalidAssertion
+	}
+
+	stored.SignCount = int64(validated.Authenticator.SignCount)
+	stored.CloneWarning = validated.Authenticator.CloneWarning
+	stored.BackupState = validated.Flags.BackupState
+
+	if err := u.repository.UpdateCredentialUsage(stored); err != nil {
+		return nil, err
+	}
+
+	// a counter that went backwards means the private key may have been copied
+	if stored.CloneWarning {
+		u.logger.Warn(ctx, "passkey.FinishLogin", "CloneWarning", "signature counter did not increase, credential blocked",
+			zap.Int64("user_id", user.id),
+			zap.Int64("credential_id", stored.ID),
+		)
+		return nil, ErrInvalidAssertion
+	}
+
+	return &Assertion{UserID: user.id, UserVerified: validated.Flags.UserVerified}, nil
+}
+
+func (u *usecase) saveSession(userID int64, ceremony string, session *webauthn.SessionData) (string, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	record := &model.WebAuthnSession{
+		ID:        utils.HashToken(raw),
+		Ceremony:  ceremony,
+		Data:      data,
+		ExpiresAt: time.Now().Add(u.sessionTTL),
+	}
+	if userID != 0 {
+		record.UserID = &userID
+	}
+
+	if err := u.repository.InsertSession(record); err != nil {
+		return "", err
+	}
+
+	return raw, nil
+}
+
+// takeSession consumes a ceremony session, checking it belongs to userID (zero
+// for passwordless logins) and has not expired.
+func (u *usecase) takeSession(raw string, ceremony string, userID int64) (*webauthn.SessionData, error) {
+	if raw == "" {
+		return nil, ErrInvalidSession
+	}
+
+	record, err := u.repository.TakeSession(utils.HashToken(raw), ceremony)
+	if err != nil {
+		return nil, err
+	}
+
+	if record == nil || time.Now().After(record.ExpiresAt) {
+		return nil, ErrInvalidSession
+	}
+
+	var owner int64
+	if record.UserID != nil {
+		owner = *record.UserID
+	}
+
+	if owner != userID {
+		return nil, ErrInvalidSession
+	}
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal(record.Data, &session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (u *usecase) loadUser(userID int64, name string) (*webAuthnUser, error) {
+	credentials, err := u.repository.GetCredentials(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webAuthnUser{id: userID, name: name, credentials: credentials}, nil
+}
+
+// webAuthnUser adapts a user and its stored credentials to webauthn.User. The
+// user handle is the decimal user id, it carries no personal data.
+type webAuthnUser struct {
+	id          int64
+	name        string
+	credentials []model.WebAuthnCredential
+}
+
+func (w *webAuthnUser) WebAuthnID() []byte {
+	return []byte(strconv.FormatInt(w.id, 10))
+}
+
+func (w *webAuthnUser) WebAuthnName() string {
+	return w.name
+}
+
+func (w *webAuthnUser) WebAuthnDisplayName() string {
+	return w.name
+}
+
+func (w *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(w.credentials))
+
+	for _, c := range w.credentials {
+		transports := make([]protocol.AuthenticatorTransport, 0, len(c.Transports))
+		for _, t := range c.Transports {
+			transports = append(transports, protocol.AuthenticatorTransport(t))
+		}
+
+		credentials = append(credentials, webauthn.Credential{
+			ID:              c.CredentialID,
+			PublicKey:       c.PublicKey,
+			AttestationType: c.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				BackupEligible: c.BackupEligible,
+				BackupState:    c.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:       c.AAGUID,
+				SignCount:    uint32(c.SignCount),
+				CloneWarning: c.CloneWarning,
+			},
+		})
+	}
+
+	return credentials
+}
+
+func (w *webAuthnUser) find(credentialID []byte) *model.WebAuthnCredential {
+	for i := range w.credentials {
+		if bytes.Equal(w.credentials[i].CredentialID, credentialID) {
+			return &w.credentials[i]
+		}
+	}
+
+	return nil
+}
+
+func transportsToStrings(transports []protocol.AuthenticatorTransport) []string {
+	res := make([]string, 0, len(transports))
+	for _, t := range transports {
+		res = append(res, string(t))
+	}
+
+	return res
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
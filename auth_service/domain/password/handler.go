@@ -0,0 +1,63 @@
+package password
+
+import (
+	"net/http"
+
+	"github.com/helyus1412/auth-service/dto"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Handler interface {
+	Forgot(c echo.Context) error
+	Reset(c echo.Context) error
+}
+
+type handler struct {
+	usecase Usecase
+	tc      trace.Tracer
+}
+
+func NewHandler(usecase Usecase, tc trace.Tracer) Handler {
+	return &handler{usecase, tc}
+}
+
+func (h *handler) Forgot(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Forgot")
+	defer span.End()
+
+	var payload dto.ForgotPasswordRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.Forgot(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "If the address is registered, a reset link has been sent", http.StatusOK, c)
+}
+
+func (h *handler) Reset(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Reset")
+	defer span.End()
+
+	var payload dto.ResetPasswordRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.Reset(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Reset Password", http.StatusOK, c)
+}
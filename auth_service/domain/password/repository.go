@@ -0,0 +1,110 @@
+package password
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/helyus1412/auth-service/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	// Insert stores a new reset token and invalidates the user's earlier ones.
+	Insert(*model.PasswordResetToken) error
+	// Get returns the unused, unexpired token with the given hash.
+	Get(hash string) (*model.PasswordResetToken, error)
+	// Reset marks token as used and sets the user's password in one
+	// transaction. It reports false when the token was used or expired in the
+	// meantime, or the user was deleted.
+	Reset(token *model.PasswordResetToken, password string, actor string) (bool, error)
+}
+
+type repository struct {
+	db     *sqlx.DB
+	schema string
+}
+
+func NewRepository(db *sqlx.DB, schema string) Repository {
+	if schema == "" {
+		schema = "public"
+	}
+
+	return &repository{db, schema}
+}
+
+func (r *repository) Insert(token *model.PasswordResetToken) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// only the most recent link works
+	_, err = tx.Exec(fmt.Sprintf(`update %s.password_reset_tokens set used_at = $1 where user_id = $2 and used_at is null`, r.schema),
+		time.Now(), token.UserID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowx(fmt.Sprintf(`INSERT INTO %s.password_reset_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3)
+		RETURNING id, created_at`, r.schema), token.UserID, token.TokenHash, token.ExpiresAt).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) Get(hash string) (*model.PasswordResetToken, error) {
+	var res model.PasswordResetToken
+
+	query, err := r.db.Preparex(fmt.Sprintf(`select * from %s.password_reset_tokens
+		where token_hash = $1 and used_at is null and expires_at > $2`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Get(&res, hash, time.Now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *repository) Reset(token *model.PasswordResetToken, password string, actor string) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	res, err := tx.Exec(fmt.Sprintf(`update %s.password_reset_tokens set used_at = $1
+		where id = $2 and used_at is null and expires_at > $1`, r.schema), now, token.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if affected, err := res.RowsAffected(); err != nil || affected != 1 {
+		return false, err
+	}
+
+	res, err = tx.Exec(fmt.Sprintf(`update %s.users set password = $1, updated_at = $2, updated_by = $3
+		where id = $4 and deleted_at is null`, r.schema), password, now, actor, token.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	if affected, err := res.RowsAffected(); err != nil || affected != 1 {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
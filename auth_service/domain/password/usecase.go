@@ -0,0 +1,191 @@
+package password
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/helyus1412/auth-service/domain/auth"
+	"github.com/helyus1412/auth-service/domain/session"
+	"github.com/helyus1412/auth-service/dto"
+	"github.com/helyus1412/auth-service/model"
+	"github.com/helyus1412/auth-service/pkg/hasher"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"github.com/helyus1412/auth-service/pkg/mailer"
+	"github.com/helyus1412/auth-service/pkg/passwordpolicy"
+	"github.com/helyus1412/auth-service/pkg/principal"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"go.uber.org/zap"
+)
+
+type Usecase interface {
+	Forgot(context.Context, *dto.ForgotPasswordRequest) utils.Result
+	Reset(context.Context, *dto.ResetPasswordRequest) utils.Result
+}
+
+type Config struct {
+	TokenTTL time.Duration
+	// ResetURL is the page that receives the token as its "token" query parameter.
+	ResetURL string
+}
+
+type usecase struct {
+	repository     Repository
+	userRepository auth.Repository
+	sessionUsecase session.Usecase
+	passwordHasher hasher.PasswordHasher
+	passwordPolicy *passwordpolicy.Policy
+	mailer         mailer.Mailer
+	config         Config
+	logger         *logger.Logger
+}
+
+func NewUsecase(repository Repository, userRepository auth.Repository, sessionUsecase session.Usecase,
+	passwordHasher hasher.PasswordHasher, passwordPolicy *passwordpolicy.Policy, mailer mailer.Mailer, config Config,
+	logger *logger.Logger) Usecase {
+	return &usecase{repository, userRepository, sessionUsecase, passwordHasher, passwordPolicy, mailer, config, logger}
+}
+
+// Forgot always succeeds so that the response does not reveal whether the
+// address belongs to an account.
+func (u *usecase) Forgot(ctx context.Context, payload *dto.ForgotPasswordRequest) (result utils.Result) {
+	user, err := u.userRepository.GetByEmail(payload.Email)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if user == nil || user.DeletedAt != nil {
+		return result
+	}
+
+	raw, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	err = u.repository.Insert(&model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(raw),
+		ExpiresAt: time.Now().Add(u.config.TokenTTL),
+	})
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	message := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("We received a request to reset the password for your account.\n\n"+
+			"Open the link below within %s to choose a new password:\n%s\n\n"+
+			"If you did not request this, you can ignore this email.\n",
+			u.config.TokenTTL, u.resetLink(raw)),
+	}
+
+	// delivery happens in the background so response time does not depend on
+	// whether the account exists
+	go func(ctx context.Context) {
+		if err := u.mailer.Send(ctx, message); err != nil {
+			u.logger.Error(ctx, "password.Forgot", "SendMail", "failed to send password reset email", err,
+				zap.Int64("user_id", user.ID))
+		}
+	}(context.WithoutCancel(ctx))
+
+	return result
+}
+
+func (u *usecase) Reset(ctx context.Context, payload *dto.ResetPasswordRequest) (result utils.Result) {
+	if payload.Token == "" || payload.Password == "" {
+		result.Error = httpError.NewBadRequest("token and password are required")
+		return result
+	}
+
+	// the token is only used up once the new password passed the policy, so a
+	// rejected password can be corrected with the same link
+	token, err := u.repository.Get(utils.HashToken(payload.Token))
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if token == nil {
+		result.Error = httpError.NewBadRequest("invalid or expired reset token")
+		return result
+	}
+
+	user, err := u.userRepository.GetByID(token.UserID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if user == nil || user.DeletedAt != nil {
+		result.Error = httpError.NewBadRequest("invalid or expired reset token")
+		return result
+	}
+
+	policyErr := auth.CheckPassword(ctx, u.userRepository, u.passwordPolicy, u.logger, user, payload.Password)
+	if policyErr != nil {
+		result.Error = policyErr
+		return result
+	}
+
+	hashedPassword, err := u.passwordHasher.Hash(payload.Password)
+	if err != nil {
+		result.Error = auth.HashError(err)
+		return result
+	}
+
+	replaced := user.Password
+
+	reset, err := u.repository.Reset(token, hashedPassword, principal.ActorSelf)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if !reset {
+		result.Error = httpError.NewBadRequest("invalid or expired reset token")
+		return result
+	}
+
+	user.Password = hashedPassword
+
+	auth.RecordPassword(ctx, u.userRepository, u.passwordPolicy, u.logger, user.ID, replaced)
+
+	// a locked account is unlocked by proving control of the mailbox
+	if user.Status == model.UserStatusLocked {
+		err = auth.Transition(u.userRepository, user, model.UserStatusActive, "password reset", principal.ActorSelf)
+		if err != nil {
+			result.Error = auth.TransitionError(err)
+			return result
+		}
+	}
+
+	// whoever knew the old password must not stay signed in
+	if err := u.sessionUsecase.RevokeUser(ctx, user.ID); err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	u.logger.Info(ctx, "password.Reset", "Reset", "password reset", zap.Int64("user_id", user.ID))
+
+	return result
+}
+
+func (u *usecase) resetLink(raw string) string {
+	link, err := url.Parse(u.config.ResetURL)
+	if err != nil {
+		return u.config.ResetURL + "?token=" + url.QueryEscape(raw)
+	}
+
+	query := link.Query()
+	query.Set("token", raw)
+	link.RawQuery = query.Encode()
+
+	return link.String()
+}
@@ -0,0 +1,169 @@
+package rbac
+
+import (
+	"net/http"
+
+	"github.com/helyus1412/auth-service/dto"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Handler interface {
+	ListRoles(c echo.Context) error
+	GetRole(c echo.Context) error
+	CreateRole(c echo.Context) error
+	UpdateRole(c echo.Context) error
+	DeleteRole(c echo.Context) error
+	ListPermissions(c echo.Context) error
+	AssignRole(c echo.Context) error
+	RevokeRole(c echo.Context) error
+}
+
+type handler struct {
+	usecase Usecase
+	tc      trace.Tracer
+}
+
+func NewHandler(usecase Usecase, tc trace.Tracer) Handler {
+	return &handler{usecase, tc}
+}
+
+func (h *handler) ListRoles(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.ListRoles")
+	defer span.End()
+
+	result := h.usecase.ListRoles(ctx)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "List Role", http.StatusOK, c)
+}
+
+func (h *handler) GetRole(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.GetRole")
+	defer span.End()
+
+	var payload dto.RoleIDRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.GetRole(ctx, payload.ID)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Detail Role", http.StatusOK, c)
+}
+
+func (h *handler) CreateRole(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.CreateRole")
+	defer span.End()
+
+	var payload dto.RoleRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.CreateRole(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Create Role", http.StatusCreated, c)
+}
+
+func (h *handler) UpdateRole(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.UpdateRole")
+	defer span.End()
+
+	var payload dto.RoleRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.UpdateRole(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Update Role", http.StatusOK, c)
+}
+
+func (h *handler) DeleteRole(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.DeleteRole")
+	defer span.End()
+
+	var payload dto.RoleIDRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.DeleteRole(ctx, payload.ID)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Delete Role", http.StatusOK, c)
+}
+
+func (h *handler) ListPermissions(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.ListPermissions")
+	defer span.End()
+
+	result := h.usecase.ListPermissions(ctx)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "List Permission", http.StatusOK, c)
+}
+
+func (h *handler) AssignRole(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.AssignRole")
+	defer span.End()
+
+	var payload dto.AssignRoleRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.AssignRole(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Assign Role", http.StatusOK, c)
+}
+
+func (h *handler) RevokeRole(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.RevokeRole")
+	defer span.End()
+
+	var payload dto.RevokeRoleRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.RevokeRole(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Revoke Role", http.StatusOK, c)
+}
@@ -0,0 +1,224 @@
+package rbac
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/helyus1412/auth-service/model"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	GetRoles() ([]model.Role, error)
+	GetRoleByID(int64) (*model.Role, error)
+	GetRoleByName(string) (*model.Role, error)
+	InsertRole(*model.Role) error
+	UpdateRole(*model.Role) error
+	DeleteRole(int64) error
+	GetPermissions() ([]model.Permission, error)
+	GetRolePermissions(int64) ([]string, error)
+	SetRolePermissions(int64, []string) error
+	GetUserRoles(int64) ([]string, error)
+	AssignRole(userID int64, roleID int64) error
+	RevokeRole(userID int64, roleID int64) error
+	HasPermission(userID int64, permission string) (bool, error)
+}
+
+type repository struct {
+	db     *sqlx.DB
+	schema string
+}
+
+func NewRepository(db *sqlx.DB, schema string) Repository {
+	if schema == "" {
+		schema = "public"
+	}
+
+	return &repository{db, schema}
+}
+
+func (r *repository) GetRoles() (roles []model.Role, err error) {
+	query, err := r.db.Preparex(fmt.Sprintf(`select * from %s.roles order by id`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Select(&roles)
+	if err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (r *repository) GetRoleByID(id int64) (*model.Role, error) {
+	return r.getRole(`id = $1`, id)
+}
+
+func (r *repository) GetRoleByName(name string) (*model.Role, error) {
+	return r.getRole(`name = $1`, name)
+}
+
+func (r *repository) getRole(condition string, arg interface{}) (*model.Role, error) {
+	var res model.Role
+
+	query, err := r.db.Preparex(fmt.Sprintf(`SELECT * from %s.roles where %s`, r.schema, condition))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Get(&res, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *repository) InsertRole(role *model.Role) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`INSERT INTO %s.roles (name, description) VALUES ($1, $2) RETURNING id, created_at`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	return queryPrep.QueryRowx(role.Name, role.Description).Scan(&role.ID, &role.CreatedAt)
+}
+
+func (r *repository) UpdateRole(role *model.Role) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.roles set name=$1, description=$2, updated_at=$3 WHERE id = $4`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(role.Name, role.Description, time.Now(), role.ID)
+
+	return err
+}
+
+func (r *repository) DeleteRole(id int64) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`delete from %s.roles where id = $1`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(id)
+
+	return err
+}
+
+func (r *repository) GetPermissions() (permissions []model.Permission, err error) {
+	query, err := r.db.Preparex(fmt.Sprintf(`select * from %s.permissions order by name`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Select(&permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+func (r *repository) GetRolePermissions(roleID int64) (names []string, err error) {
+	query, err := r.db.Preparex(fmt.Sprintf(`select p.name from %[1]s.permissions p
+		join %[1]s.role_permissions rp on rp.permission_id = p.id
+		where rp.role_id = $1 order by p.name`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Select(&names, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// SetRolePermissions replaces the permissions granted to a role. Unknown
+// permission names are ignored.
+func (r *repository) SetRolePermissions(roleID int64, names []string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(fmt.Sprintf(`delete from %s.role_permissions where role_id = $1`, r.schema), roleID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(fmt.Sprintf(`insert into %[1]s.role_permissions (role_id, permission_id)
+		select $1, id from %[1]s.permissions where name = any($2)`, r.schema), roleID, pq.Array(names))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) GetUserRoles(userID int64) (names []string, err error) {
+	query, err := r.db.Preparex(fmt.Sprintf(`select r.name from %[1]s.roles r
+		join %[1]s.user_roles ur on ur.role_id = r.id
+		where ur.user_id = $1 order by r.name`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Select(&names, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+func (r *repository) AssignRole(userID int64, roleID int64) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`INSERT INTO %s.user_roles (user_id, role_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(userID, roleID)
+
+	return err
+}
+
+func (r *repository) RevokeRole(userID int64, roleID int64) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`delete from %s.user_roles where user_id = $1 and role_id = $2`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(userID, roleID)
+
+	return err
+}
+
+func (r *repository) HasPermission(userID int64, permission string) (bool, error) {
+	var exists bool
+
+	query, err := r.db.Preparex(fmt.Sprintf(`select exists (
+		select 1 from %[1]s.user_roles ur
+		join %[1]s.role_permissions rp on rp.role_id = ur.role_id
+		join %[1]s.permissions p on p.id = rp.permission_id
+		where ur.user_id = $1 and p.name = $2)`, r.schema))
+	if err != nil {
+		return false, err
+	}
+
+	err = query.Get(&exists, userID, permission)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,244 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+
+	"github.com/helyus1412/auth-service/dto"
+	"github.com/helyus1412/auth-service/model"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"github.com/lib/pq"
+)
+
+type Usecase interface {
+	ListRoles(context.Context) utils.Result
+	GetRole(context.Context, int64) utils.Result
+	CreateRole(context.Context, *dto.RoleRequest) utils.Result
+	UpdateRole(context.Context, *dto.RoleRequest) utils.Result
+	DeleteRole(context.Context, int64) utils.Result
+	ListPermissions(context.Context) utils.Result
+	AssignRole(context.Context, *dto.AssignRoleRequest) utils.Result
+	RevokeRole(context.Context, *dto.RevokeRoleRequest) utils.Result
+}
+
+type usecase struct {
+	repository Repository
+}
+
+func NewUsecase(repository Repository) Usecase {
+	return &usecase{repository}
+}
+
+func (u *usecase) ListRoles(ctx context.Context) (result utils.Result) {
+	roles, err := u.repository.GetRoles()
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	for i := range roles {
+		roles[i].Permissions, err = u.repository.GetRolePermissions(roles[i].ID)
+		if err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+	}
+
+	result.Data = roles
+
+	return result
+}
+
+func (u *usecase) GetRole(ctx context.Context, id int64) (result utils.Result) {
+	role, err := u.repository.GetRoleByID(id)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if role == nil {
+		result.Error = httpError.NewNotFound("role not found")
+		return result
+	}
+
+	role.Permissions, err = u.repository.GetRolePermissions(role.ID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	result.Data = role
+
+	return result
+}
+
+func (u *usecase) CreateRole(ctx context.Context, payload *dto.RoleRequest) (result utils.Result) {
+	if payload.Name == "" {
+		result.Error = httpError.NewBadRequest("role name is required")
+		return result
+	}
+
+	existing, err := u.repository.GetRoleByName(payload.Name)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if existing != nil {
+		result.Error = httpError.NewConflict("role already exists")
+		return result
+	}
+
+	role := &model.Role{
+		Name:        payload.Name,
+		Description: payload.Description,
+	}
+
+	err = u.repository.InsertRole(role)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if payload.Permissions != nil {
+		err = u.repository.SetRolePermissions(role.ID, payload.Permissions)
+		if err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+	}
+
+	return u.GetRole(ctx, role.ID)
+}
+
+func (u *usecase) UpdateRole(ctx context.Context, payload *dto.RoleRequest) (result utils.Result) {
+	role, err := u.repository.GetRoleByID(payload.ID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if role == nil {
+		result.Error = httpError.NewNotFound("role not found")
+		return result
+	}
+
+	if payload.Name != "" && payload.Name != role.Name {
+		if role.IsSystem {
+			result.Error = httpError.NewBadRequest("system roles cannot be renamed")
+			return result
+		}
+
+		existing, err := u.repository.GetRoleByName(payload.Name)
+		if err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+
+		if existing != nil {
+			result.Error = httpError.NewConflict("role already exists")
+			return result
+		}
+
+		role.Name = payload.Name
+	}
+
+	if payload.Description != nil {
+		role.Description = payload.Description
+	}
+
+	err = u.repository.UpdateRole(role)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if payload.Permissions != nil {
+		err = u.repository.SetRolePermissions(role.ID, payload.Permissions)
+		if err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+	}
+
+	return u.GetRole(ctx, role.ID)
+}
+
+func (u *usecase) DeleteRole(ctx context.Context, id int64) (result utils.Result) {
+	role, err := u.repository.GetRoleByID(id)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if role == nil {
+		result.Error = httpError.NewNotFound("role not found")
+		return result
+	}
+
+	if role.IsSystem {
+		result.Error = httpError.NewBadRequest("system roles cannot be deleted")
+		return result
+	}
+
+	err = u.repository.DeleteRole(id)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	return result
+}
+
+func (u *usecase) ListPermissions(ctx context.Context) (result utils.Result) {
+	permissions, err := u.repository.GetPermissions()
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	result.Data = permissions
+
+	return result
+}
+
+func (u *usecase) AssignRole(ctx context.Context, payload *dto.AssignRoleRequest) (result utils.Result) {
+	role, err := u.repository.GetRoleByName(payload.Role)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if role == nil {
+		result.Error = httpError.NewNotFound("role not found")
+		return result
+	}
+
+	err = u.repository.AssignRole(payload.UserID, role.ID)
+	if isForeignKeyViolation(err) {
+		result.Error = httpError.NewNotFound("user not found")
+		return result
+	}
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	return result
+}
+
+func (u *usecase) RevokeRole(ctx context.Context, payload *dto.RevokeRoleRequest) (result utils.Result) {
+	err := u.repository.RevokeRole(payload.UserID, payload.RoleID)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	return result
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
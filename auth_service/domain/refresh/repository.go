@@ -0,0 +1,120 @@
+package refresh
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/helyus1412/auth-service/model"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	Insert(*model.RefreshToken) error
+	GetByHash(string) (*model.RefreshToken, error)
+	MarkRotated(int64) (bool, error)
+	RevokeFamily(string) error
+	RevokeUser(int64) error
+	RevokeSession(string) error
+}
+
+type repository struct {
+	db     *sqlx.DB
+	schema string
+}
+
+func NewRepository(db *sqlx.DB, schema string) Repository {
+	if schema == "" {
+		schema = "public"
+	}
+
+	return &repository{db, schema}
+}
+
+func (r *repository) Insert(token *model.RefreshToken) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`INSERT INTO %s.refresh_tokens (user_id, family_id, session_id, client_id, scope, amr, token_hash, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	if token.AMR == nil {
+		token.AMR = pq.StringArray{}
+	}
+
+	return queryPrep.QueryRowx(token.UserID, token.FamilyID, token.SessionID, token.ClientID, token.Scope, token.AMR, token.TokenHash, token.ExpiresAt).Scan(&token.ID, &token.CreatedAt)
+}
+
+func (r *repository) GetByHash(hash string) (*model.RefreshToken, error) {
+	var res model.RefreshToken
+
+	query, err := r.db.Preparex(fmt.Sprintf(`SELECT * from %s.refresh_tokens where token_hash = $1`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Get(&res, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// MarkRotated flags a token as used. It reports false when the token had
+// already been rotated or revoked, which lets callers detect concurrent reuse.
+func (r *repository) MarkRotated(id int64) (bool, error) {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.refresh_tokens set rotated_at = $1 where id = $2 and rotated_at is null and revoked_at is null`, r.schema))
+	if err != nil {
+		return false, err
+	}
+
+	res, err := queryPrep.Exec(time.Now(), id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *repository) RevokeFamily(familyID string) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.refresh_tokens set revoked_at = $1 where family_id = $2 and revoked_at is null`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(time.Now(), familyID)
+
+	return err
+}
+
+func (r *repository) RevokeUser(userID int64) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.refresh_tokens set revoked_at = $1 where user_id = $2 and revoked_at is null`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(time.Now(), userID)
+
+	return err
+}
+
+func (r *repository) RevokeSession(sessionID string) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.refresh_tokens set revoked_at = $1 where session_id = $2 and revoked_at is null`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(time.Now(), sessionID)
+
+	return err
+}
@@ -0,0 +1,185 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/helyus1412/auth-service/model"
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid refresh token")
+	ErrExpiredToken = errors.New("refresh token expired")
+	ErrTokenReused  = errors.New("refresh token reuse detected")
+)
+
+type Usecase interface {
+	// Issue starts a new token family for the user's session and returns the
+	// opaque token. amr lists the authentication methods used at login and is
+	// carried over to access tokens minted from this family.
+	Issue(ctx context.Context, userID int64, sessionID string, amr []string) (string, error)
+	// IssueForClient starts a new token family bound to an OAuth client and scope.
+	IssueForClient(ctx context.Context, userID int64, clientID string, scope string) (string, error)
+	// Rotate consumes a refresh token and returns its successor in the same family.
+	// clientID must match the client the token was issued to, or be empty for
+	// first-party tokens issued by /login.
+	Rotate(ctx context.Context, raw string, clientID string) (*model.RefreshToken, string, error)
+	// RevokeUser revokes every refresh token the user holds.
+	RevokeUser(ctx context.Context, userID int64) error
+	// RevokeSession revokes every refresh token issued to the session.
+	RevokeSession(ctx context.Context, sessionID string) error
+	// Revoke revokes the token family of raw. Unknown tokens and tokens issued
+	// to another client, empty for first-party tokens, are ignored.
+	Revoke(ctx context.Context, raw string, clientID string) error
+	// Lookup returns the token for raw when it can still be rotated, nil otherwise.
+	Lookup(ctx context.Context, raw string) (*model.RefreshToken, error)
+}
+
+type usecase struct {
+	repository Repository
+	ttl        time.Duration
+	logger     *logger.Logger
+}
+
+func NewUsecase(repository Repository, ttl time.Duration, logger *logger.Logger) Usecase {
+	return &usecase{repository, ttl, logger}
+}
+
+func (u *usecase) Issue(ctx context.Context, userID int64, sessionID string, amr []string) (string, error) {
+	return u.issue(&model.RefreshToken{UserID: userID, FamilyID: uuid.NewString(), SessionID: &sessionID, AMR: amr})
+}
+
+func (u *usecase) IssueForClient(ctx context.Context, userID int64, clientID string, scope string) (string, error) {
+	return u.issue(&model.RefreshToken{
+		UserID:   userID,
+		FamilyID: uuid.NewString(),
+		ClientID: &clientID,
+		Scope:    &scope,
+	})
+}
+
+func (u *usecase) Rotate(ctx context.Context, raw string, clientID string) (*model.RefreshToken, string, error) {
+	current, err := u.repository.GetByHash(utils.HashToken(raw))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if current == nil || current.RevokedAt != nil {
+		return nil, "", ErrInvalidToken
+	}
+
+	if current.ClientID == nil && clientID != "" || current.ClientID != nil && *current.ClientID != clientID {
+		return nil, "", ErrInvalidToken
+	}
+
+	if current.RotatedAt != nil {
+		u.revokeReusedFamily(ctx, current)
+		return nil, "", ErrTokenReused
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return nil, "", ErrExpiredToken
+	}
+
+	rotated, err := u.repository.MarkRotated(current.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// another request rotated the same token between our read and update
+	if !rotated {
+		u.revokeReusedFamily(ctx, current)
+		return nil, "", ErrTokenReused
+	}
+
+	next, err := u.issue(&model.RefreshToken{
+		UserID:    current.UserID,
+		FamilyID:  current.FamilyID,
+		SessionID: current.SessionID,
+		ClientID:  current.ClientID,
+		Scope:     current.Scope,
+		AMR:       current.AMR,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return current, next, nil
+}
+
+func (u *usecase) RevokeUser(ctx context.Context, userID int64) error {
+	return u.repository.RevokeUser(userID)
+}
+
+func (u *usecase) RevokeSession(ctx context.Context, sessionID string) error {
+	return u.repository.RevokeSession(sessionID)
+}
+
+func (u *usecase) Revoke(ctx context.Context, raw string, clientID string) error {
+	current, err := u.repository.GetByHash(utils.HashToken(raw))
+	if err != nil {
+		return err
+	}
+
+	if current == nil || current.ClientID == nil && clientID != "" || current.ClientID != nil && *current.ClientID != clientID {
+		return nil
+	}
+
+	return u.repository.RevokeFamily(current.FamilyID)
+}
+
+func (u *usecase) Lookup(ctx context.Context, raw string) (*model.RefreshToken, error) {
+	current, err := u.repository.GetByHash(utils.HashToken(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	if current == nil || current.RevokedAt != nil || current.RotatedAt != nil || time.Now().After(current.ExpiresAt) {
+		return nil, nil
+	}
+
+	return current, nil
+}
+
+func (u *usecase) issue(token *model.RefreshToken) (string, error) {
+	raw, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	token.TokenHash = utils.HashToken(raw)
+	token.ExpiresAt = time.Now().Add(u.ttl)
+
+	err = u.repository.Insert(token)
+	if err != nil {
+		return "", err
+	}
+
+	return raw, nil
+}
+
+func (u *usecase) revokeReusedFamily(ctx context.Context, token *model.RefreshToken) {
+	u.logger.Warn(ctx,
+		"refresh.Rotate",
+		"ReuseDetected",
+		"rotated refresh token presented again, revoking token family",
+		zap.Int64("user_id", token.UserID),
+		zap.String("family_id", token.FamilyID),
+		zap.Int64("token_id", token.ID),
+	)
+
+	if err := u.repository.RevokeFamily(token.FamilyID); err != nil {
+		u.logger.Error(ctx,
+			"refresh.Rotate",
+			"RevokeFamily",
+			"failed to revoke refresh token family",
+			err,
+			zap.String("family_id", token.FamilyID),
+		)
+	}
+}
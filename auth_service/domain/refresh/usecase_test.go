@@ -0,0 +1,282 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/helyus1412/auth-service/model"
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"go.uber.org/zap/zapcore"
+)
+
+// memoryRepository keeps refresh tokens in a slice. raceRotation makes
+// MarkRotated report the token as rotated by a concurrent request.
+type memoryRepository struct {
+	tokens       []*model.RefreshToken
+	raceRotation bool
+}
+
+func (r *memoryRepository) Insert(token *model.RefreshToken) error {
+	token.ID = int64(len(r.tokens) + 1)
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *memoryRepository) GetByHash(hash string) (*model.RefreshToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *memoryRepository) MarkRotated(id int64) (bool, error) {
+	token := r.tokens[id-1]
+	if r.raceRotation || token.RotatedAt != nil || token.RevokedAt != nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	token.RotatedAt = &now
+
+	return true, nil
+}
+
+func (r *memoryRepository) last() *model.RefreshToken {
+	return r.tokens[len(r.tokens)-1]
+}
+
+func (r *memoryRepository) RevokeFamily(familyID string) error {
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryRepository) RevokeUser(userID int64) error {
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryRepository) RevokeSession(sessionID string) error {
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.SessionID != nil && *token.SessionID == sessionID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+
+	return nil
+}
+
+func newTestUsecase(t *testing.T, repository Repository) Usecase {
+	t.Helper()
+
+	log, err := logger.New(logger.Config{LogLevel: zapcore.FatalLevel})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewUsecase(repository, time.Hour, log)
+}
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		name string
+		// prepare returns the token presented to Rotate
+		prepare func(t *testing.T, u Usecase, repository *memoryRepository) string
+		want    error
+		// familyRevoked means every token of the presented family is revoked
+		familyRevoked bool
+	}{
+		{
+			name: "fresh token",
+			prepare: func(t *testing.T, u Usecase, repository *memoryRepository) string {
+				return issue(t, u)
+			},
+		},
+		{
+			name: "successor of a rotated token",
+			prepare: func(t *testing.T, u Usecase, repository *memoryRepository) string {
+				return rotate(t, u, issue(t, u))
+			},
+		},
+		{
+			name: "unknown token",
+			prepare: func(t *testing.T, u Usecase, repository *memoryRepository) string {
+				return "unknown"
+			},
+			want: ErrInvalidToken,
+		},
+		{
+			name: "expired token",
+			prepare: func(t *testing.T, u Usecase, repository *memoryRepository) string {
+				raw := issue(t, u)
+				repository.last().ExpiresAt = time.Now().Add(-time.Second)
+				return raw
+			},
+			want: ErrExpiredToken,
+		},
+		{
+			name: "revoked token",
+			prepare: func(t *testing.T, u Usecase, repository *memoryRepository) string {
+				raw := issue(t, u)
+				repository.RevokeFamily(repository.last().FamilyID)
+				return raw
+			},
+			want: ErrInvalidToken,
+		},
+		{
+			name: "reuse of a rotated token",
+			prepare: func(t *testing.T, u Usecase, repository *memoryRepository) string {
+				raw := issue(t, u)
+				rotate(t, u, raw)
+				return raw
+			},
+			want:          ErrTokenReused,
+			familyRevoked: true,
+		},
+		{
+			name: "reuse of a token rotated twice",
+			prepare: func(t *testing.T, u Usecase, repository *memoryRepository) string {
+				raw := issue(t, u)
+				rotate(t, u, rotate(t, u, raw))
+				return raw
+			},
+			want:          ErrTokenReused,
+			familyRevoked: true,
+		},
+		{
+			name: "concurrent rotation",
+			prepare: func(t *testing.T, u Usecase, repository *memoryRepository) string {
+				raw := issue(t, u)
+				repository.raceRotation = true
+				return raw
+			},
+			want:          ErrTokenReused,
+			familyRevoked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &memoryRepository{}
+			u := newTestUsecase(t, repository)
+
+			// reuse must only revoke the affected family
+			other := issue(t, u)
+
+			raw := tt.prepare(t, u, repository)
+
+			current, next, err := u.Rotate(context.Background(), raw, "")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Rotate() error = %v, want %v", err, tt.want)
+			}
+
+			if tt.want == nil && (current == nil || next == "") {
+				t.Fatalf("Rotate() = %v, %q, want the current token and its successor", current, next)
+			}
+
+			for _, token := range repository.tokens {
+				if token.TokenHash == utils.HashToken(other) {
+					if token.RevokedAt != nil {
+						t.Fatal("reuse revoked another token family")
+					}
+					continue
+				}
+
+				if tt.familyRevoked && token.RevokedAt == nil {
+					t.Fatalf("token %d of the reused family is not revoked", token.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestRotateKeepsFamily(t *testing.T) {
+	repository := &memoryRepository{}
+	u := newTestUsecase(t, repository)
+
+	next := rotate(t, u, issue(t, u))
+
+	current, _, err := u.Rotate(context.Background(), next, "")
+	if err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+
+	if current.FamilyID != repository.tokens[0].FamilyID || current.UserID != 1 || !slices.Equal(current.AMR, []string{"pwd", "otp"}) {
+		t.Fatalf("successor left the family: %+v", current)
+	}
+}
+
+func TestRotateChecksClient(t *testing.T) {
+	tests := []struct {
+		name     string
+		issuedTo string
+		client   string
+		want     error
+	}{
+		{name: "first-party token"},
+		{name: "client token", issuedTo: "app", client: "app"},
+		{name: "first-party token presented by a client", client: "app", want: ErrInvalidToken},
+		{name: "client token presented first-party", issuedTo: "app", want: ErrInvalidToken},
+		{name: "client token presented by another client", issuedTo: "app", client: "other", want: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestUsecase(t, &memoryRepository{})
+
+			raw, err := u.Issue(context.Background(), 1, "session", []string{"pwd", "otp"})
+			if tt.issuedTo != "" {
+				raw, err = u.IssueForClient(context.Background(), 1, tt.issuedTo, "openid")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, _, err := u.Rotate(context.Background(), raw, tt.client); !errors.Is(err, tt.want) {
+				t.Fatalf("Rotate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func issue(t *testing.T, u Usecase) string {
+	t.Helper()
+
+	raw, err := u.Issue(context.Background(), 1, "session", []string{"pwd", "otp"})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	return raw
+}
+
+func rotate(t *testing.T, u Usecase, raw string) string {
+	t.Helper()
+
+	_, next, err := u.Rotate(context.Background(), raw, "")
+	if err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+
+	return next
+}
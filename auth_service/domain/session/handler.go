@@ -0,0 +1,89 @@
+package session
+
+import (
+	"net/http"
+
+	"github.com/helyus1412/auth-service/dto"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Handler interface {
+	List(c echo.Context) error
+	Revoke(c echo.Context) error
+	RevokeAll(c echo.Context) error
+	Logout(c echo.Context) error
+}
+
+type handler struct {
+	usecase Usecase
+	tc      trace.Tracer
+}
+
+func NewHandler(usecase Usecase, tc trace.Tracer) Handler {
+	return &handler{usecase, tc}
+}
+
+func (h *handler) List(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.ListSessions")
+	defer span.End()
+
+	result := h.usecase.List(ctx)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success List Sessions", http.StatusOK, c)
+}
+
+func (h *handler) Revoke(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.RevokeSession")
+	defer span.End()
+
+	var payload dto.SessionIDRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.Revoke(ctx, payload.ID)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Revoke Session", http.StatusOK, c)
+}
+
+func (h *handler) RevokeAll(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.RevokeAllSessions")
+	defer span.End()
+
+	result := h.usecase.RevokeAll(ctx)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Revoke All Sessions", http.StatusOK, c)
+}
+
+func (h *handler) Logout(c echo.Context) error {
+	ctx, span := h.tc.Start(c.Request().Context(), "handler.Logout")
+	defer span.End()
+
+	var payload dto.LogoutRequest
+
+	if err := c.Bind(&payload); err != nil {
+		respErr := httpError.NewBadRequest("")
+		return utils.ResponseError(respErr, c)
+	}
+
+	result := h.usecase.Logout(ctx, &payload)
+	if result.Error != nil {
+		return utils.ResponseError(result.Error, c)
+	}
+
+	return utils.Response(result.Data, "Success Logout", http.StatusOK, c)
+}
@@ -0,0 +1,122 @@
+package session
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/helyus1412/auth-service/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	Insert(*model.Session) error
+	GetByID(string) (*model.Session, error)
+	GetActiveByUserID(userID int64, since time.Time) ([]model.Session, error)
+	Touch(id string, ip string) (bool, error)
+	Revoke(string) error
+	RevokeUser(int64) error
+}
+
+type repository struct {
+	db     *sqlx.DB
+	schema string
+}
+
+func NewRepository(db *sqlx.DB, schema string) Repository {
+	if schema == "" {
+		schema = "public"
+	}
+
+	return &repository{db, schema}
+}
+
+func (r *repository) Insert(session *model.Session) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`INSERT INTO %s.sessions (id, user_id, device, user_agent, ip) VALUES ($1, $2, $3, $4, $5) RETURNING created_at, last_seen_at`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	return queryPrep.QueryRowx(session.ID, session.UserID, session.Device, session.UserAgent, session.IP).Scan(&session.CreatedAt, &session.LastSeenAt)
+}
+
+func (r *repository) GetByID(id string) (*model.Session, error) {
+	var res model.Session
+
+	query, err := r.db.Preparex(fmt.Sprintf(`SELECT * from %s.sessions where id = $1`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Get(&res, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetActiveByUserID returns the user's sessions that are not revoked and were
+// seen after since, most recently used first.
+func (r *repository) GetActiveByUserID(userID int64, since time.Time) ([]model.Session, error) {
+	res := []model.Session{}
+
+	query, err := r.db.Preparex(fmt.Sprintf(`SELECT * from %s.sessions where user_id = $1 and revoked_at is null and last_seen_at > $2 order by last_seen_at desc`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Select(&res, userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Touch records activity on a session. It reports false when the session is
+// missing or revoked.
+func (r *repository) Touch(id string, ip string) (bool, error) {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.sessions set last_seen_at = $1, ip = $2 where id = $3 and revoked_at is null`, r.schema))
+	if err != nil {
+		return false, err
+	}
+
+	res, err := queryPrep.Exec(time.Now(), ip, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *repository) Revoke(id string) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.sessions set revoked_at = $1 where id = $2 and revoked_at is null`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(time.Now(), id)
+
+	return err
+}
+
+func (r *repository) RevokeUser(userID int64) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.sessions set revoked_at = $1 where user_id = $2 and revoked_at is null`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(time.Now(), userID)
+
+	return err
+}
@@ -0,0 +1,240 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/helyus1412/auth-service/domain/refresh"
+	"github.com/helyus1412/auth-service/dto"
+	"github.com/helyus1412/auth-service/model"
+	"github.com/helyus1412/auth-service/pkg/denylist"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"github.com/helyus1412/auth-service/pkg/principal"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"go.uber.org/zap"
+)
+
+var ErrRevoked = errors.New("session revoked")
+
+const maxUserAgentLength = 512
+
+type Usecase interface {
+	// Start records a new sign-in of the user from the given client.
+	Start(ctx context.Context, userID int64, ip string, userAgent string) (*model.Session, error)
+	// Touch records activity on the session. It returns ErrRevoked when the
+	// session was revoked, in which case its refresh tokens are revoked again
+	// to catch any rotated concurrently with the revocation.
+	Touch(ctx context.Context, id string, ip string) error
+	// RevokeUser signs the user out everywhere.
+	RevokeUser(ctx context.Context, userID int64) error
+	List(context.Context) utils.Result
+	Revoke(context.Context, string) utils.Result
+	RevokeAll(context.Context) utils.Result
+	// Logout ends the caller's session and revokes the access token it used.
+	Logout(context.Context, *dto.LogoutRequest) utils.Result
+}
+
+type Config struct {
+	// IdleTTL hides sessions unused for this long, their refresh tokens have
+	// expired by then.
+	IdleTTL time.Duration
+}
+
+type usecase struct {
+	repository     Repository
+	refreshUsecase refresh.Usecase
+	denylist       denylist.Denylist
+	config         Config
+	logger         *logger.Logger
+}
+
+func NewUsecase(repository Repository, refreshUsecase refresh.Usecase, denylist denylist.Denylist, config Config,
+	logger *logger.Logger) Usecase {
+	return &usecase{repository, refreshUsecase, denylist, config, logger}
+}
+
+func (u *usecase) Start(ctx context.Context, userID int64, ip string, userAgent string) (*model.Session, error) {
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	session := &model.Session{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Device:    describeDevice(userAgent),
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+
+	if err := u.repository.Insert(session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (u *usecase) Touch(ctx context.Context, id string, ip string) error {
+	touched, err := u.repository.Touch(id, ip)
+	if err != nil {
+		return err
+	}
+
+	if touched {
+		return nil
+	}
+
+	if err := u.refreshUsecase.RevokeSession(ctx, id); err != nil {
+		return err
+	}
+
+	return ErrRevoked
+}
+
+// RevokeUser marks the sessions before revoking the refresh tokens so a token
+// rotated in between is caught by Touch.
+func (u *usecase) RevokeUser(ctx context.Context, userID int64) error {
+	if err := u.repository.RevokeUser(userID); err != nil {
+		return err
+	}
+
+	return u.refreshUsecase.RevokeUser(ctx, userID)
+}
+
+func (u *usecase) List(ctx context.Context) (result utils.Result) {
+	actor, ok := principal.FromContext(ctx)
+	if !ok {
+		result.Error = httpError.NewUnauthorized("")
+		return result
+	}
+
+	sessions, err := u.repository.GetActiveByUserID(actor.UserID, time.Now().Add(-u.config.IdleTTL))
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == actor.SessionID
+	}
+
+	result.Data = sessions
+
+	return result
+}
+
+func (u *usecase) Revoke(ctx context.Context, id string) (result utils.Result) {
+	actor, ok := principal.FromContext(ctx)
+	if !ok {
+		result.Error = httpError.NewUnauthorized("")
+		return result
+	}
+
+	if uuid.Validate(id) != nil {
+		result.Error = httpError.NewNotFound("session not found")
+		return result
+	}
+
+	session, err := u.repository.GetByID(id)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	// sessions of other users are reported as missing rather than forbidden
+	if session == nil || session.UserID != actor.UserID || session.RevokedAt != nil {
+		result.Error = httpError.NewNotFound("session not found")
+		return result
+	}
+
+	if err := u.repository.Revoke(session.ID); err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if err := u.refreshUsecase.RevokeSession(ctx, session.ID); err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if session.ID == actor.SessionID {
+		if err := u.denyAccessToken(ctx, actor); err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+	}
+
+	u.logger.Info(ctx, "session.Revoke", "Revoked", "session revoked",
+		zap.Int64("user_id", actor.UserID), zap.String("session_id", session.ID))
+
+	return result
+}
+
+func (u *usecase) RevokeAll(ctx context.Context) (result utils.Result) {
+	actor, ok := principal.FromContext(ctx)
+	if !ok {
+		result.Error = httpError.NewUnauthorized("")
+		return result
+	}
+
+	if err := u.RevokeUser(ctx, actor.UserID); err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	if err := u.denyAccessToken(ctx, actor); err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	u.logger.Info(ctx, "session.RevokeAll", "Revoked", "signed out of every session",
+		zap.Int64("user_id", actor.UserID))
+
+	return result
+}
+
+func (u *usecase) Logout(ctx context.Context, payload *dto.LogoutRequest) (result utils.Result) {
+	actor, ok := principal.FromContext(ctx)
+	if !ok {
+		result.Error = httpError.NewUnauthorized("")
+		return result
+	}
+
+	if actor.SessionID != "" {
+		if err := u.repository.Revoke(actor.SessionID); err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+
+		if err := u.refreshUsecase.RevokeSession(ctx, actor.SessionID); err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+	}
+
+	// tokens issued before sessions were recorded are revoked by value
+	if payload.RefreshToken != "" {
+		if err := u.refreshUsecase.Revoke(ctx, payload.RefreshToken, ""); err != nil {
+			result.Error = httpError.NewInternalServerError(err.Error())
+			return result
+		}
+	}
+
+	if err := u.denyAccessToken(ctx, actor); err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	return result
+}
+
+// denyAccessToken revokes the access token of the current request until it expires.
+func (u *usecase) denyAccessToken(ctx context.Context, actor *principal.Principal) error {
+	if actor.TokenID == "" {
+		return nil
+	}
+
+	return u.denylist.Add(ctx, actor.TokenID, actor.ExpiresAt)
+}
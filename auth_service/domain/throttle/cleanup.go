@@ -0,0 +1,34 @@
+package throttle
+
+import (
+	"context"
+	"time"
+
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RunCleanup removes the login counters that no longer count towards a
+// block, every interval until ctx is done. Without it a row stays behind for
+// every user and IP that ever failed a login.
+func RunCleanup(ctx context.Context, repository Repository, interval time.Duration, window time.Duration, logger *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := repository.DeleteExpired(window)
+			if err != nil {
+				logger.Error(ctx, "throttle.RunCleanup", "DeleteExpired", "failed to delete expired login attempts", err)
+				continue
+			}
+
+			if deleted > 0 {
+				logger.Info(ctx, "throttle.RunCleanup", "DeleteExpired", "deleted expired login attempts", zap.Int64("deleted", deleted))
+			}
+		}
+	}
+}
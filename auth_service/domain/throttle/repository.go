@@ -0,0 +1,122 @@
+package throttle
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/helyus1412/auth-service/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository is the store for failed login counters.
+type Repository interface {
+	Get(scope string, key string) (*model.LoginAttempt, error)
+	// RecordFailure counts a failed attempt and returns the updated counter. The
+	// count starts over when the previous failure is older than window or an
+	// earlier lock has expired.
+	RecordFailure(scope string, key string, window time.Duration) (*model.LoginAttempt, error)
+	Lock(scope string, key string, until time.Time) error
+	Reset(scope string, key string) error
+	// DeleteExpired removes the counters whose last failure is older than
+	// window and whose lock, if any, has run out. It returns how many were
+	// removed.
+	DeleteExpired(window time.Duration) (int64, error)
+}
+
+type repository struct {
+	db     *sqlx.DB
+	schema string
+}
+
+func NewRepository(db *sqlx.DB, schema string) Repository {
+	if schema == "" {
+		schema = "public"
+	}
+
+	return &repository{db, schema}
+}
+
+func (r *repository) Get(scope string, key string) (*model.LoginAttempt, error) {
+	var res model.LoginAttempt
+
+	query, err := r.db.Preparex(fmt.Sprintf(`SELECT * from %s.login_attempts where scope = $1 and key = $2`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Get(&res, scope, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *repository) RecordFailure(scope string, key string, window time.Duration) (*model.LoginAttempt, error) {
+	var res model.LoginAttempt
+
+	query, err := r.db.Preparex(fmt.Sprintf(`INSERT INTO %s.login_attempts AS la (scope, key, failures, last_failed_at)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			failures = CASE WHEN la.last_failed_at < $4 OR la.locked_until <= $3 THEN 1 ELSE la.failures + 1 END,
+			locked_until = CASE WHEN la.locked_until <= $3 THEN NULL ELSE la.locked_until END,
+			last_failed_at = $3
+		RETURNING *`, r.schema))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	err = query.Get(&res, scope, key, now, now.Add(-window))
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *repository) Lock(scope string, key string, until time.Time) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`update %s.login_attempts set locked_until = $1 where scope = $2 and key = $3`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(until, scope, key)
+
+	return err
+}
+
+func (r *repository) Reset(scope string, key string) error {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`delete from %s.login_attempts where scope = $1 and key = $2`, r.schema))
+	if err != nil {
+		return err
+	}
+
+	_, err = queryPrep.Exec(scope, key)
+
+	return err
+}
+
+func (r *repository) DeleteExpired(window time.Duration) (int64, error) {
+	queryPrep, err := r.db.Preparex(fmt.Sprintf(`delete from %s.login_attempts
+		where last_failed_at < $1 and (locked_until is null or locked_until <= $2)`, r.schema))
+	if err != nil {
+		return 0, err
+	}
+	defer queryPrep.Close()
+
+	now := time.Now()
+
+	res, err := queryPrep.Exec(now.Add(-window), now)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
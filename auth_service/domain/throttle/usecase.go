@@ -0,0 +1,189 @@
+package throttle
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/helyus1412/auth-service/model"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"github.com/helyus1412/auth-service/pkg/principal"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	scopeAccount = "account"
+	scopeIP      = "ip"
+)
+
+// Block describes why a login attempt may not proceed yet.
+type Block struct {
+	// Locked is set when the account itself reached the failure threshold.
+	// Otherwise the caller waits out a backoff or a lock on its address.
+	Locked bool
+	Until  time.Time
+}
+
+type Usecase interface {
+	// Check reports whether a login for the account (zero when unknown) from
+	// ip must be refused for now. It returns nil when the attempt may proceed.
+	Check(ctx context.Context, userID int64, ip string) (*Block, error)
+	RecordFailure(ctx context.Context, userID int64, ip string) error
+	// RecordSuccess clears the account's counter. The IP counter is left to
+	// expire so a valid login cannot be used to reset it.
+	RecordSuccess(ctx context.Context, userID int64) error
+	Unlock(context.Context, int64) utils.Result
+}
+
+type Config struct {
+	// MaxAttempts locks an account after this many consecutive failures.
+	MaxAttempts int
+	// IPMaxAttempts locks a client address after this many consecutive failures.
+	IPMaxAttempts int
+	LockDuration  time.Duration
+	// BackoffBase is the wait after the first failure, doubling with every
+	// further failure up to BackoffMax.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// Window is how long a failure is remembered when no further ones follow.
+	Window time.Duration
+}
+
+type usecase struct {
+	repository Repository
+	config     Config
+	logger     *logger.Logger
+}
+
+func NewUsecase(repository Repository, config Config, logger *logger.Logger) Usecase {
+	return &usecase{repository, config, logger}
+}
+
+func (u *usecase) Check(ctx context.Context, userID int64, ip string) (*Block, error) {
+	now := time.Now()
+
+	for _, target := range u.targets(userID, ip) {
+		attempt, err := u.repository.Get(target.scope, target.key)
+		if err != nil {
+			return nil, err
+		}
+
+		if block := u.block(target, attempt, now); block != nil {
+			return block, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (u *usecase) RecordFailure(ctx context.Context, userID int64, ip string) error {
+	for _, target := range u.targets(userID, ip) {
+		attempt, err := u.repository.RecordFailure(target.scope, target.key, u.config.Window)
+		if err != nil {
+			return err
+		}
+
+		u.logger.Warn(ctx, "throttle.RecordFailure", "LoginFailed", "failed login attempt",
+			zap.String("scope", target.scope),
+			zap.String("key", target.key),
+			zap.Int("failures", attempt.Failures),
+		)
+
+		if attempt.LockedUntil != nil || attempt.Failures < target.maxAttempts {
+			continue
+		}
+
+		until := time.Now().Add(u.config.LockDuration)
+		if err := u.repository.Lock(target.scope, target.key, until); err != nil {
+			return err
+		}
+
+		u.logger.Warn(ctx, "throttle.RecordFailure", "Locked", "too many failed logins, locked",
+			zap.String("scope", target.scope),
+			zap.String("key", target.key),
+			zap.Time("locked_until", until),
+		)
+	}
+
+	return nil
+}
+
+func (u *usecase) RecordSuccess(ctx context.Context, userID int64) error {
+	return u.repository.Reset(scopeAccount, strconv.FormatInt(userID, 10))
+}
+
+func (u *usecase) Unlock(ctx context.Context, userID int64) (result utils.Result) {
+	err := u.repository.Reset(scopeAccount, strconv.FormatInt(userID, 10))
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	fields := []zap.Field{zap.Int64("user_id", userID)}
+	if actor, ok := principal.FromContext(ctx); ok {
+		fields = append(fields, zap.Int64("actor_id", actor.UserID))
+	}
+
+	u.logger.Info(ctx, "throttle.Unlock", "Unlocked", "account unlocked by administrator", fields...)
+
+	return result
+}
+
+// block returns when the next attempt is allowed, or nil if it already is.
+func (u *usecase) block(target target, attempt *model.LoginAttempt, now time.Time) *Block {
+	if attempt == nil {
+		return nil
+	}
+
+	if attempt.LockedUntil != nil {
+		if now.Before(*attempt.LockedUntil) {
+			return &Block{Locked: target.scope == scopeAccount, Until: *attempt.LockedUntil}
+		}
+		return nil
+	}
+
+	if attempt.LastFailedAt.Before(now.Add(-u.config.Window)) {
+		return nil
+	}
+
+	next := attempt.LastFailedAt.Add(u.backoff(attempt.Failures))
+	if now.Before(next) {
+		return &Block{Until: next}
+	}
+
+	return nil
+}
+
+func (u *usecase) backoff(failures int) time.Duration {
+	wait := u.config.BackoffBase
+	for i := 1; i < failures; i++ {
+		wait *= 2
+		if wait >= u.config.BackoffMax {
+			return u.config.BackoffMax
+		}
+	}
+
+	return wait
+}
+
+type target struct {
+	scope       string
+	key         string
+	maxAttempts int
+}
+
+func (u *usecase) targets(userID int64, ip string) []target {
+	targets := make([]target, 0, 2)
+
+	if userID != 0 {
+		targets = append(targets, target{scopeAccount, strconv.FormatInt(userID, 10), u.config.MaxAttempts})
+	}
+
+	if ip != "" {
+		targets = append(targets, target{scopeIP, ip, u.config.IPMaxAttempts})
+	}
+
+	return targets
+}
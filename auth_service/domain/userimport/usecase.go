@@ -0,0 +1,171 @@
+package userimport
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/helyus1412/auth-service/domain/auth"
+	"github.com/helyus1412/auth-service/domain/rbac"
+	"github.com/helyus1412/auth-service/dto"
+	"github.com/helyus1412/auth-service/model"
+	"github.com/helyus1412/auth-service/pkg/hasher"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"github.com/helyus1412/auth-service/pkg/principal"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// MaxBatchSize is the most users accepted by a single Import call.
+const MaxBatchSize = 1000
+
+const formatFirebaseScrypt = "firebase_scrypt"
+
+type Usecase interface {
+	// Import creates users with password hashes from another system. Users are
+	// imported one by one, failures are reported per user and do not stop the
+	// batch. The hashes are upgraded to the current algorithm on first login.
+	Import(context.Context, *dto.ImportUsersRequest) utils.Result
+}
+
+type usecase struct {
+	userRepository auth.Repository
+	rbacRepository rbac.Repository
+	passwordHasher hasher.PasswordHasher
+	logger         *logger.Logger
+}
+
+func NewUsecase(userRepository auth.Repository, rbacRepository rbac.Repository, passwordHasher hasher.PasswordHasher,
+	logger *logger.Logger) Usecase {
+	return &usecase{userRepository, rbacRepository, passwordHasher, logger}
+}
+
+func (u *usecase) Import(ctx context.Context, payload *dto.ImportUsersRequest) (result utils.Result) {
+	if len(payload.Users) == 0 {
+		result.Error = httpError.NewBadRequest("users are required")
+		return result
+	}
+
+	if len(payload.Users) > MaxBatchSize {
+		result.Error = httpError.NewBadRequest("too many users, split the import into batches")
+		return result
+	}
+
+	role, err := u.rbacRepository.GetRoleByName(auth.DefaultRole)
+	if err != nil {
+		result.Error = httpError.NewInternalServerError(err.Error())
+		return result
+	}
+
+	response := &dto.ImportUsersResponse{Failed: []dto.ImportFailure{}}
+	actor := principal.ActorFrom(ctx, principal.ActorSystem)
+
+	for i, imported := range payload.Users {
+		reason, err := u.importUser(imported, role, actor)
+		if err != nil {
+			u.logger.Error(ctx, "userimport.Import", "importUser", "failed to store imported user", err, zap.Int("index", i))
+			reason = storeFailure(err)
+		}
+
+		if reason != "" {
+			response.Failed = append(response.Failed, dto.ImportFailure{Index: i, Email: imported.Email, Error: reason})
+			continue
+		}
+
+		response.Imported++
+	}
+
+	fields := []zap.Field{zap.Int("imported", response.Imported), zap.Int("failed", len(response.Failed))}
+	if actor, ok := principal.FromContext(ctx); ok {
+		fields = append(fields, zap.Int64("actor_id", actor.UserID))
+	}
+
+	u.logger.Info(ctx, "userimport.Import", "Imported", "users imported", fields...)
+
+	result.Data = response
+
+	return result
+}
+
+// importUser returns why the user was skipped, or the error that kept it from
+// being stored. A user is either stored with its role or not at all, so a
+// failed row can be retried.
+func (u *usecase) importUser(imported dto.ImportUser, role *model.Role, actor string) (string, error) {
+	if imported.Email == "" {
+		return "email is required", nil
+	}
+
+	encoded := imported.PasswordHash
+	if imported.HashFormat == formatFirebaseScrypt {
+		if imported.Salt == "" {
+			return "salt is required for firebase_scrypt", nil
+		}
+
+		encoded = hasher.FirebaseScryptHash(imported.Salt, imported.PasswordHash)
+	}
+
+	if encoded == "" || !u.passwordHasher.Supports(encoded) {
+		return "unsupported password hash format", nil
+	}
+
+	// every login verifies with the parameters of the hash
+	if err := hasher.CheckCost(encoded); errors.Is(err, hasher.ErrCostTooHigh) {
+		return "password hash parameters exceed the allowed cost", nil
+	} else if err != nil {
+		return "malformed password hash", nil
+	}
+
+	existing, err := u.userRepository.GetByEmail(imported.Email)
+	if err != nil {
+		return "", err
+	}
+
+	if existing != nil {
+		return "email already exists", nil
+	}
+
+	user := &model.User{
+		Email:    imported.Email,
+		Password: encoded,
+	}
+
+	if imported.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := u.userRepository.Insert(user, actor); err != nil {
+		return "", err
+	}
+
+	if role != nil {
+		if err := u.rbacRepository.AssignRole(user.ID, role.ID); err != nil {
+			if purgeErr := u.userRepository.Purge(user.ID); purgeErr != nil {
+				return "", errors.Join(err, purgeErr)
+			}
+
+			return "", err
+		}
+	}
+
+	return "", nil
+}
+
+// storeFailure is the reason reported for a user the database refused.
+func storeFailure(err error) string {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return "user could not be stored"
+	}
+
+	switch pqErr.Code {
+	case "23505":
+		return "email already exists"
+	case "22001":
+		return "email is too long"
+	default:
+		return "user could not be stored"
+	}
+}
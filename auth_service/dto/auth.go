@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/helyus1412/auth-service/model"
+)
+
 type RegisterRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
@@ -8,6 +14,9 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	// IP and UserAgent describe the client, filled in by the handler
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 type EditRequest struct {
@@ -16,6 +25,74 @@ type EditRequest struct {
 	Password string `json:"password"`
 }
 
+type ListUsersRequest struct {
+	Page  int `query:"page"`
+	Limit int `query:"limit"`
+	// Cursor is the meta.nextCursor of the previous page. It replaces Page and
+	// keeps pages stable while users are added or removed.
+	Cursor string `query:"cursor"`
+	// Email matches users whose address contains it, ignoring case.
+	Email string `query:"email"`
+	// CreatedFrom and CreatedTo are RFC 3339 times or dates, both inclusive.
+	CreatedFrom    string `query:"created_from"`
+	CreatedTo      string `query:"created_to"`
+	IncludeDeleted bool   `query:"include_deleted"`
+	// Sort is id, email or created_at, prefixed with - for descending order.
+	Sort string `query:"sort"`
+}
+
+type SearchUsersRequest struct {
+	// Query is matched against email and the created_by, updated_by and
+	// deleted_by columns, tolerating typos.
+	Query          string `query:"q"`
+	Page           int    `query:"page"`
+	Limit          int    `query:"limit"`
+	IncludeDeleted bool   `query:"include_deleted"`
+}
+
+type UserSearchResult struct {
+	model.User
+	Score float64 `json:"score"`
+	// Highlight maps each matching field to its value with the matches wrapped
+	// in <mark> tags, HTML escaped otherwise.
+	Highlight map[string]string `json:"highlight,omitempty"`
+}
+
 type DeleteRequest struct {
 	ID int64 `param:"id"`
 }
+
+type UserStatusRequest struct {
+	ID     int64  `param:"id"`
+	Reason string `json:"reason"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	// IP is the client address, filled in by the handler
+	IP string `json:"-"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `query:"token"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+type UnlockRequest struct {
+	ID int64 `param:"id"`
+}
+
+type LoginBlockedResponse struct {
+	RetryAfter  int64      `json:"retry_after"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+import "encoding/json"
+
+type TOTPEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFAChallengeResponse struct {
+	MFARequired bool     `json:"mfa_required"`
+	MFAToken    string   `json:"mfa_token"`
+	Methods     []string `json:"methods"`
+}
+
+type LoginMFARequest struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+	// SessionID and Credential answer a passkey challenge from /login/mfa/passkey
+	SessionID  string          `json:"session_id"`
+	Credential json.RawMessage `json:"credential"`
+	// IP and UserAgent describe the client, filled in by the handler
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
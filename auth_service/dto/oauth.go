@@ -0,0 +1,115 @@
+package dto
+
+import (
+	"encoding/json"
+
+	"github.com/helyus1412/auth-service/model"
+)
+
+type AuthorizeRequest struct {
+	ResponseType        string `query:"response_type" form:"response_type" json:"response_type"`
+	ClientID            string `query:"client_id" form:"client_id" json:"client_id"`
+	RedirectURI         string `query:"redirect_uri" form:"redirect_uri" json:"redirect_uri"`
+	Scope               string `query:"scope" form:"scope" json:"scope"`
+	State               string `query:"state" form:"state" json:"state"`
+	CodeChallenge       string `query:"code_challenge" form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `query:"code_challenge_method" form:"code_challenge_method" json:"code_challenge_method"`
+	Nonce               string `query:"nonce" form:"nonce" json:"nonce"`
+	Email               string `form:"email" json:"email"`
+	Password            string `form:"password" json:"password"`
+	// Decision is "approve" or "deny"; it may be left empty when the user
+	// already consented to the requested scopes.
+	Decision string `form:"decision" json:"decision"`
+	// MFAToken answers the challenge returned for users with a second factor,
+	// together with MFACode, RecoveryCode or a passkey assertion from
+	// /login/mfa/passkey. Email and Password are not needed then.
+	MFAToken     string          `form:"mfa_token" json:"mfa_token"`
+	MFACode      string          `form:"mfa_code" json:"mfa_code"`
+	RecoveryCode string          `form:"recovery_code" json:"recovery_code"`
+	SessionID    string          `form:"session_id" json:"session_id"`
+	Credential   json.RawMessage `json:"credential"`
+	// IP and UserAgent describe the client, filled in by the handler
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+type AuthorizeResponse struct {
+	ClientID        string   `json:"client_id"`
+	ClientName      string   `json:"client_name"`
+	RedirectURI     string   `json:"redirect_uri"`
+	Scopes          []string `json:"scopes"`
+	State           string   `json:"state,omitempty"`
+	ConsentRequired bool     `json:"consent_required"`
+}
+
+// AuthorizeRedirect tells the handler to send the user agent back to the client.
+type AuthorizeRedirect struct {
+	URL string
+}
+
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type" json:"grant_type"`
+	Code         string `form:"code" json:"code"`
+	RedirectURI  string `form:"redirect_uri" json:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier" json:"code_verifier"`
+	RefreshToken string `form:"refresh_token" json:"refresh_token"`
+	Scope        string `form:"scope" json:"scope"`
+	ClientID     string `form:"client_id" json:"client_id"`
+	ClientSecret string `form:"client_secret" json:"client_secret"`
+}
+
+type OAuthRevokeRequest struct {
+	Token         string `form:"token" json:"token"`
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint"`
+	ClientID      string `form:"client_id" json:"client_id"`
+	ClientSecret  string `form:"client_secret" json:"client_secret"`
+}
+
+type OAuthIntrospectRequest struct {
+	Token         string `form:"token" json:"token"`
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint"`
+	ClientID      string `form:"client_id" json:"client_id"`
+	ClientSecret  string `form:"client_secret" json:"client_secret"`
+}
+
+// OAuthIntrospectResponse is an RFC 7662 response. Inactive tokens carry
+// nothing but active: false.
+type OAuthIntrospectResponse struct {
+	Active    bool     `json:"active"`
+	Scope     string   `json:"scope,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	Exp       int64    `json:"exp,omitempty"`
+	Iat       int64    `json:"iat,omitempty"`
+	Sub       string   `json:"sub,omitempty"`
+	Aud       []string `json:"aud,omitempty"`
+	Iss       string   `json:"iss,omitempty"`
+	Jti       string   `json:"jti,omitempty"`
+}
+
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+type CreateClientRequest struct {
+	Name           string   `json:"name"`
+	RedirectURIs   []string `json:"redirect_uris"`
+	GrantTypes     []string `json:"grant_types"`
+	Scopes         []string `json:"scopes"`
+	IsConfidential bool     `json:"is_confidential"`
+}
+
+type CreateClientResponse struct {
+	model.OAuthClient
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+type ClientIDRequest struct {
+	ClientID string `param:"clientId"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "encoding/json"
+
+// PasskeyOptionsResponse carries the options for navigator.credentials.create()
+// or navigator.credentials.get() and the session to finish the ceremony with.
+type PasskeyOptionsResponse struct {
+	SessionID string      `json:"session_id"`
+	Options   interface{} `json:"options"`
+}
+
+type PasskeyFinishRequest struct {
+	SessionID  string          `json:"session_id"`
+	Credential json.RawMessage `json:"credential"`
+	Name       string          `json:"name"`
+	// IP and UserAgent describe the client when finishing a login, filled in
+	// by the handler
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+type LoginMFAPasskeyRequest struct {
+	MFAToken string `json:"mfa_token"`
+}
+
+type PasskeyIDRequest struct {
+	ID int64 `param:"id"`
+}
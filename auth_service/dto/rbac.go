@@ -0,0 +1,22 @@
+package dto
+
+type RoleRequest struct {
+	ID          int64    `param:"id"`
+	Name        string   `json:"name"`
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type RoleIDRequest struct {
+	ID int64 `param:"id"`
+}
+
+type AssignRoleRequest struct {
+	UserID int64  `param:"id"`
+	Role   string `json:"role"`
+}
+
+type RevokeRoleRequest struct {
+	UserID int64 `param:"id"`
+	RoleID int64 `param:"roleId"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+type SessionIDRequest struct {
+	ID string `param:"id"`
+}
+
+type LogoutRequest struct {
+	// RefreshToken is optional, the refresh tokens of the current session are
+	// revoked regardless
+	RefreshToken string `json:"refresh_token"`
+}
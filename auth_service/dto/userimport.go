@@ -0,0 +1,26 @@
+package dto
+
+type ImportUser struct {
+	Email        string `json:"email"`
+	PasswordHash string `json:"password_hash"`
+	// HashFormat is only needed for firebase_scrypt, whose hash and salt are
+	// exported separately. Other formats are recognised from the hash itself.
+	HashFormat    string `json:"hash_format"`
+	Salt          string `json:"salt"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+type ImportUsersRequest struct {
+	Users []ImportUser `json:"users"`
+}
+
+type ImportFailure struct {
+	Index int    `json:"index"`
+	Email string `json:"email"`
+	Error string `json:"error"`
+}
+
+type ImportUsersResponse struct {
+	Imported int             `json:"imported"`
+	Failed   []ImportFailure `json:"failed"`
+}
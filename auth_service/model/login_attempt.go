@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// LoginAttempt counts consecutive failed logins for an account or a client IP.
+type LoginAttempt struct {
+	Scope        string     `db:"scope" json:"scope"`
+	Key          string     `db:"key" json:"key"`
+	Failures     int        `db:"failures" json:"failures"`
+	LastFailedAt time.Time  `db:"last_failed_at" json:"last_failed_at"`
+	LockedUntil  *time.Time `db:"locked_until" json:"locked_until"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type UserTOTP struct {
+	UserID          int64      `db:"user_id" json:"user_id"`
+	SecretEncrypted []byte     `db:"secret_encrypted" json:"-"`
+	LastUsedStep    int64      `db:"last_used_step" json:"-"`
+	ConfirmedAt     *time.Time `db:"confirmed_at" json:"confirmed_at"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt       *time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type RecoveryCode struct {
+	ID        int64      `db:"id" json:"id"`
+	UserID    int64      `db:"user_id" json:"user_id"`
+	CodeHash  string     `db:"code_hash" json:"-"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type OAuthClient struct {
+	ID               int64          `db:"id" json:"id"`
+	ClientID         string         `db:"client_id" json:"client_id"`
+	ClientSecretHash *string        `db:"client_secret_hash" json:"-"`
+	Name             string         `db:"name" json:"name"`
+	RedirectURIs     pq.StringArray `db:"redirect_uris" json:"redirect_uris"`
+	GrantTypes       pq.StringArray `db:"grant_types" json:"grant_types"`
+	Scopes           pq.StringArray `db:"scopes" json:"scopes"`
+	IsConfidential   bool           `db:"is_confidential" json:"is_confidential"`
+	CreatedAt        time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt        *time.Time     `db:"updated_at" json:"updated_at"`
+	DeletedAt        *time.Time     `db:"deleted_at" json:"deleted_at"`
+}
+
+type AuthorizationCode struct {
+	ID                  int64      `db:"id" json:"id"`
+	CodeHash            string     `db:"code_hash" json:"-"`
+	ClientID            string     `db:"client_id" json:"client_id"`
+	UserID              int64      `db:"user_id" json:"user_id"`
+	RedirectURI         string     `db:"redirect_uri" json:"redirect_uri"`
+	RedirectURIExplicit bool       `db:"redirect_uri_explicit" json:"-"`
+	Scope               string     `db:"scope" json:"scope"`
+	CodeChallenge       string     `db:"code_challenge" json:"-"`
+	CodeChallengeMethod string     `db:"code_challenge_method" json:"code_challenge_method"`
+	Nonce               *string    `db:"nonce" json:"-"`
+	AuthTime            time.Time  `db:"auth_time" json:"auth_time"`
+	ExpiresAt           time.Time  `db:"expires_at" json:"expires_at"`
+	CreatedAt           time.Time  `db:"created_at" json:"created_at"`
+	UsedAt              *time.Time `db:"used_at" json:"used_at"`
+}
+
+type Consent struct {
+	UserID    int64      `db:"user_id" json:"user_id"`
+	ClientID  string     `db:"client_id" json:"client_id"`
+	Scope     string     `db:"scope" json:"scope"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt *time.Time `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type RefreshToken struct {
+	ID        int64          `db:"id" json:"id"`
+	UserID    int64          `db:"user_id" json:"user_id"`
+	FamilyID  string         `db:"family_id" json:"family_id"`
+	SessionID *string        `db:"session_id" json:"session_id"`
+	ClientID  *string        `db:"client_id" json:"client_id"`
+	Scope     *string        `db:"scope" json:"scope"`
+	AMR       pq.StringArray `db:"amr" json:"amr"`
+	TokenHash string         `db:"token_hash" json:"-"`
+	ExpiresAt time.Time      `db:"expires_at" json:"expires_at"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+	RotatedAt *time.Time     `db:"rotated_at" json:"rotated_at"`
+	RevokedAt *time.Time     `db:"revoked_at" json:"revoked_at"`
+}
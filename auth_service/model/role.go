@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+type Role struct {
+	ID          int64      `db:"id" json:"id"`
+	Name        string     `db:"name" json:"name"`
+	Description *string    `db:"description" json:"description"`
+	IsSystem    bool       `db:"is_system" json:"is_system"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   *time.Time `db:"updated_at" json:"updated_at"`
+	Permissions []string   `db:"-" json:"permissions"`
+}
+
+type Permission struct {
+	ID          int64   `db:"id" json:"id"`
+	Name        string  `db:"name" json:"name"`
+	Description *string `db:"description" json:"description"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Session is one sign-in of a user on a device. Its refresh tokens are
+// revoked together with it.
+type Session struct {
+	ID         string     `db:"id" json:"id"`
+	UserID     int64      `db:"user_id" json:"user_id"`
+	Device     string     `db:"device" json:"device"`
+	UserAgent  string     `db:"user_agent" json:"user_agent"`
+	IP         string     `db:"ip" json:"ip"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	LastSeenAt time.Time  `db:"last_seen_at" json:"last_seen_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	// Current marks the session the listing was requested from.
+	Current bool `db:"-" json:"current"`
+}
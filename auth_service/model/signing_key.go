@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+const (
+	SigningKeyStateNext    = "next"
+	SigningKeyStateActive  = "active"
+	SigningKeyStateRetired = "retired"
+)
+
+// SigningKey is a token signing key. The private key is stored encrypted under
+// the master key. A retired key stays published until RetiredAt so tokens
+// signed shortly before a rotation keep verifying.
+type SigningKey struct {
+	ID                  int64      `db:"id" json:"id"`
+	KeyID               string     `db:"kid" json:"kid"`
+	Algorithm           string     `db:"algorithm" json:"algorithm"`
+	PrivateKeyEncrypted []byte     `db:"private_key_encrypted" json:"-"`
+	PublicKey           []byte     `db:"public_key" json:"-"`
+	State               string     `db:"state" json:"state"`
+	CreatedAt           time.Time  `db:"created_at" json:"created_at"`
+	ActivatedAt         *time.Time `db:"activated_at" json:"activated_at"`
+	RetiredAt           *time.Time `db:"retired_at" json:"retired_at"`
+}
@@ -2,14 +2,44 @@ package model
 
 import "time"
 
+// Account statuses, see auth.CanTransition for the allowed changes.
+const (
+	// UserStatusPending accounts have not verified their email address yet.
+	UserStatusPending = "pending"
+	UserStatusActive  = "active"
+	// UserStatusSuspended accounts are frozen by an administrator, e.g. during
+	// a fraud investigation.
+	UserStatusSuspended = "suspended"
+	// UserStatusLocked accounts must reset their password to sign in again.
+	UserStatusLocked  = "locked"
+	UserStatusDeleted = "deleted"
+)
+
 type User struct {
-	ID        int64      `db:"id" json:"id"`
-	Email     string     `db:"email" json:"email"`
-	Password  string     `db:"password" json:"-"`
-	CreatedAt time.Time  `db:"created_at" json:"created_at"`
-	UpdatedAt *time.Time `db:"updated_at" json:"updated_at"`
-	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at"`
-	CreatedBy *string    `db:"created_by" json:"created_by"`
-	UpdatedBy *string    `db:"updated_by" json:"updated_by"`
-	DeletedBy *string    `db:"deleted_by" json:"deleted_by"`
+	ID       int64  `db:"id" json:"id"`
+	Email    string `db:"email" json:"email"`
+	Password string `db:"password" json:"-"`
+	Status   string `db:"status" json:"status"`
+	// EmailVerifiedAt is set once the user opened the link sent to Email
+	EmailVerifiedAt *time.Time `db:"email_verified_at" json:"email_verified_at"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt       *time.Time `db:"updated_at" json:"updated_at"`
+	DeletedAt       *time.Time `db:"deleted_at" json:"deleted_at"`
+	CreatedBy       *string    `db:"created_by" json:"created_by"`
+	UpdatedBy       *string    `db:"updated_by" json:"updated_by"`
+	DeletedBy       *string    `db:"deleted_by" json:"deleted_by"`
+	// AnonymizedAt is set once the personal data of a deleted user was erased
+	AnonymizedAt *time.Time `db:"anonymized_at" json:"anonymized_at"`
+}
+
+// UserStatusChange records who moved a user from one status to another and
+// why.
+type UserStatusChange struct {
+	ID         int64     `db:"id" json:"id"`
+	UserID     int64     `db:"user_id" json:"user_id"`
+	FromStatus string    `db:"from_status" json:"from_status"`
+	ToStatus   string    `db:"to_status" json:"to_status"`
+	Reason     string    `db:"reason" json:"reason"`
+	Actor      string    `db:"actor" json:"actor"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
 }
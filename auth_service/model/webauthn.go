@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type WebAuthnCredential struct {
+	ID              int64          `db:"id" json:"id"`
+	UserID          int64          `db:"user_id" json:"user_id"`
+	CredentialID    []byte         `db:"credential_id" json:"-"`
+	PublicKey       []byte         `db:"public_key" json:"-"`
+	AttestationType string         `db:"attestation_type" json:"attestation_type"`
+	Transports      pq.StringArray `db:"transports" json:"transports"`
+	AAGUID          []byte         `db:"aaguid" json:"-"`
+	SignCount       int64          `db:"sign_count" json:"sign_count"`
+	CloneWarning    bool           `db:"clone_warning" json:"clone_warning"`
+	BackupEligible  bool           `db:"backup_eligible" json:"backup_eligible"`
+	BackupState     bool           `db:"backup_state" json:"backup_state"`
+	Name            string         `db:"name" json:"name"`
+	CreatedAt       time.Time      `db:"created_at" json:"created_at"`
+	LastUsedAt      *time.Time     `db:"last_used_at" json:"last_used_at"`
+}
+
+// WebAuthnSession holds the server side state of a registration or login
+// ceremony between its begin and finish calls.
+type WebAuthnSession struct {
+	ID        string    `db:"id" json:"-"`
+	UserID    *int64    `db:"user_id" json:"user_id"`
+	Ceremony  string    `db:"ceremony" json:"ceremony"`
+	Data      []byte    `db:"data" json:"-"`
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,57 @@
+package breach
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// RangeAPI queries a Pwned Passwords compatible range API. Only the first 5
+// characters of the password's SHA-1 are sent, the match happens locally.
+type RangeAPI struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewRangeAPI queries baseURL, e.g. https://api.pwnedpasswords.com or a
+// service serving NewRangeHandler.
+func NewRangeAPI(baseURL string, client *http.Client) *RangeAPI {
+	return &RangeAPI{strings.TrimSuffix(baseURL, "/"), client}
+}
+
+func (a *RangeAPI) Count(ctx context.Context, password string) (int, error) {
+	digest := Digest(password)
+	prefix, suffix := digest[:PrefixLength], digest[PrefixLength:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseURL+"/range/"+prefix, nil)
+	if err != nil {
+		return 0, err
+	}
+	// padding hides the real size of the response from an observer
+	req.Header.Set("Add-Padding", "true")
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("breach: range api responded with status %d", res.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		candidate, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(candidate, suffix) {
+			continue
+		}
+
+		return strconv.Atoi(count)
+	}
+
+	return 0, scanner.Err()
+}
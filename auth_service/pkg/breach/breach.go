@@ -0,0 +1,39 @@
+package breach
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrMalformedFile   = errors.New("breach: malformed dataset file")
+	ErrMalformedPrefix = errors.New("breach: prefix must be 5 hexadecimal characters")
+)
+
+// PrefixLength is the number of hex characters of a SHA-1 digest sent to a
+// range API, the remaining suffix never leaves the service.
+const PrefixLength = 5
+
+// Checker looks passwords up in a corpus of passwords exposed in data
+// breaches.
+type Checker interface {
+	// Count returns how often password appears in the corpus, zero when it
+	// does not.
+	Count(ctx context.Context, password string) (int, error)
+}
+
+// Entry is one line of a range response.
+type Entry struct {
+	Suffix string
+	Count  int
+}
+
+// Digest returns the upper case hex SHA-1 of password, the form used by
+// Pwned Passwords.
+func Digest(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
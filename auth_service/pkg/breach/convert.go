@@ -0,0 +1,65 @@
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Convert reads the "SHA1:COUNT" lines of a Pwned Passwords download ordered
+// by hash and writes the records OpenFile reads. It returns the number of
+// records written.
+func Convert(w io.Writer, r io.Reader) (int64, error) {
+	scanner := bufio.NewScanner(r)
+	out := bufio.NewWriter(w)
+
+	var previous []byte
+	var written int64
+	record := make([]byte, recordSize)
+	digest := record[:sha1.Size]
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		encoded, rawCount, ok := strings.Cut(text, ":")
+		if !ok || len(encoded) != hex.EncodedLen(sha1.Size) {
+			return written, fmt.Errorf("line %d: expected SHA1:COUNT", line)
+		}
+
+		if _, err := hex.Decode(digest, []byte(encoded)); err != nil {
+			return written, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		count, err := strconv.ParseUint(rawCount, 10, 32)
+		if err != nil {
+			return written, fmt.Errorf("line %d: %w", line, err)
+		}
+		binary.BigEndian.PutUint32(record[sha1.Size:], uint32(count))
+
+		// lookups binary search the records
+		if previous != nil && bytes.Compare(previous, digest) >= 0 {
+			return written, fmt.Errorf("line %d: input is not ordered by hash", line)
+		}
+		previous = append(previous[:0], digest...)
+
+		if _, err := out.Write(record); err != nil {
+			return written, err
+		}
+		written++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return written, err
+	}
+
+	return written, out.Flush()
+}
@@ -0,0 +1,137 @@
+package breach
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+)
+
+// recordSize is a SHA-1 digest followed by its big endian uint32 count.
+const recordSize = sha1.Size + 4
+
+// File is a dataset of fixed size records sorted by digest, as written by
+// Convert. It is memory mapped where the platform supports it so that only
+// the pages touched by the binary search are ever read.
+type File struct {
+	data    io.ReaderAt
+	records int64
+	close   func() error
+}
+
+// OpenFile opens a dataset written by Convert.
+func OpenFile(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if info.Size()%recordSize != 0 {
+		f.Close()
+		return nil, ErrMalformedFile
+	}
+
+	data, closeFn, err := mapFile(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &File{data, info.Size() / recordSize, closeFn}, nil
+}
+
+func (f *File) Close() error {
+	return f.close()
+}
+
+func (f *File) Count(ctx context.Context, password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+
+	i, err := f.search(sum[:])
+	if err != nil || i == f.records {
+		return 0, err
+	}
+
+	digest, count, err := f.record(i)
+	if err != nil || !bytes.Equal(digest, sum[:]) {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Range returns every entry whose digest starts with the 5 hex character
+// prefix, answering like the Pwned Passwords range API.
+func (f *File) Range(prefix string) ([]Entry, error) {
+	if len(prefix) != PrefixLength {
+		return nil, ErrMalformedPrefix
+	}
+
+	// pad to whole bytes, the smallest digest with the prefix ends in zeros
+	start, err := hex.DecodeString(prefix + "0")
+	if err != nil {
+		return nil, ErrMalformedPrefix
+	}
+
+	i, err := f.search(start)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for ; i < f.records; i++ {
+		digest, count, err := f.record(i)
+		if err != nil {
+			return nil, err
+		}
+
+		encoded := strings.ToUpper(hex.EncodeToString(digest))
+		if !strings.EqualFold(encoded[:PrefixLength], prefix) {
+			break
+		}
+
+		entries = append(entries, Entry{encoded[PrefixLength:], count})
+	}
+
+	return entries, nil
+}
+
+// search returns the index of the first record whose digest is not below key.
+func (f *File) search(key []byte) (int64, error) {
+	low, high := int64(0), f.records
+	for low < high {
+		mid := low + (high-low)/2
+
+		digest, _, err := f.record(mid)
+		if err != nil {
+			return 0, err
+		}
+
+		if bytes.Compare(digest[:len(key)], key) < 0 {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+
+	return low, nil
+}
+
+func (f *File) record(i int64) ([]byte, int, error) {
+	buf := make([]byte, recordSize)
+	if _, err := f.data.ReadAt(buf, i*recordSize); err != nil {
+		return nil, 0, err
+	}
+
+	return buf[:sha1.Size], int(binary.BigEndian.Uint32(buf[sha1.Size:])), nil
+}
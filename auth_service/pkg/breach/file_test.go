@@ -0,0 +1,207 @@
+package breach
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// openDataset converts the "SHA1:COUNT" lines of text and opens the result.
+func openDataset(t *testing.T, text string) *File {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "pwned.bin")
+
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Convert(out, strings.NewReader(text)); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	return file
+}
+
+func openFixture(t *testing.T) *File {
+	t.Helper()
+
+	text, err := os.ReadFile(filepath.Join("testdata", "pwned-passwords.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return openDataset(t, string(text))
+}
+
+func TestFileCount(t *testing.T) {
+	file := openFixture(t)
+
+	tests := []struct {
+		password string
+		want     int
+	}{
+		{"password", 9545824},
+		{"123456", 37359195},
+		{"qwerty", 10556095},
+		// between two records
+		{"letmein", 0},
+		// between the last two records
+		{"hunter2", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			got, err := file.Count(context.Background(), tt.password)
+			if err != nil {
+				t.Fatalf("Count() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("Count() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileCountPastLastRecord(t *testing.T) {
+	// every digest sorts after the only record
+	file := openDataset(t, "0000000000000000000000000000000000000000:1\n")
+
+	got, err := file.Count(context.Background(), "password")
+	if err != nil || got != 0 {
+		t.Fatalf("Count() = %d, %v, want 0", got, err)
+	}
+}
+
+func TestFileCountEmpty(t *testing.T) {
+	file := openDataset(t, "")
+
+	got, err := file.Count(context.Background(), "password")
+	if err != nil || got != 0 {
+		t.Fatalf("Count() = %d, %v, want 0", got, err)
+	}
+}
+
+func TestFileRange(t *testing.T) {
+	file := openFixture(t)
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   []Entry
+	}{
+		{
+			name:   "first records",
+			prefix: "00000",
+			want: []Entry{
+				{"00000000000000000000000000000000000", 1},
+				{"A1B2C3D4E5F60718293A4B5C6D7E8F90011", 7},
+			},
+		},
+		{
+			name:   "prefix right after the first",
+			prefix: "00001",
+			want:   []Entry{{"000000000000000000000000000000000FF", 2}},
+		},
+		{
+			name:   "middle",
+			prefix: "5BAA6",
+			want: []Entry{
+				{"1E4C9B93F3F0682250B6CF8331B7EE68FD8", 9545824},
+				{"1E4C9B93F3F0682250B6CF8331B7EE68FD9", 3},
+			},
+		},
+		{
+			name:   "lower case",
+			prefix: "5baa6",
+			want: []Entry{
+				{"1E4C9B93F3F0682250B6CF8331B7EE68FD8", 9545824},
+				{"1E4C9B93F3F0682250B6CF8331B7EE68FD9", 3},
+			},
+		},
+		{
+			name:   "last records",
+			prefix: "FFFFF",
+			want: []Entry{
+				{"00000000000000000000000000000000000", 5},
+				{"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF", 4294967295},
+			},
+		},
+		{name: "below a present prefix", prefix: "5BAA5"},
+		{name: "above a present prefix", prefix: "5BAA7"},
+		{name: "between the first prefixes", prefix: "00002"},
+		{name: "below the last prefix", prefix: "FFFFE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := file.Range(tt.prefix)
+			if err != nil {
+				t.Fatalf("Range() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Range() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileRangeMalformedPrefix(t *testing.T) {
+	file := openFixture(t)
+
+	for _, prefix := range []string{"", "5BAA", "5BAA61", "5BAAG"} {
+		if _, err := file.Range(prefix); !errors.Is(err, ErrMalformedPrefix) {
+			t.Errorf("Range(%q) error = %v, want ErrMalformedPrefix", prefix, err)
+		}
+	}
+}
+
+func TestOpenFileMalformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pwned.bin")
+	if err := os.WriteFile(path, make([]byte, recordSize+1), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenFile(path); !errors.Is(err, ErrMalformedFile) {
+		t.Fatalf("OpenFile() error = %v, want ErrMalformedFile", err)
+	}
+}
+
+func TestConvertRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"missing count", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8\n"},
+		{"short digest", "5BAA61E4:1\n"},
+		{"not hex", "ZBAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1\n"},
+		{"count too large", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:4294967296\n"},
+		{"unordered", "7C4A8D09CA3762AF61E59520943DC26494F8941B:1\n5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1\n"},
+		{"duplicate", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1\n5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Convert(&strings.Builder{}, strings.NewReader(tt.text)); err == nil {
+				t.Fatal("Convert() error = nil")
+			}
+		})
+	}
+}
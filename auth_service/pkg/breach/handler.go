@@ -0,0 +1,34 @@
+package breach
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+)
+
+// NewRangeHandler serves GET /range/{prefix} from file the way the Pwned
+// Passwords API does, for environments that cannot reach it.
+func NewRangeHandler(file *File) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /range/{prefix}", func(w http.ResponseWriter, r *http.Request) {
+		entries, err := file.Range(r.PathValue("prefix"))
+		if err == ErrMalformedPrefix {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, "failed to read dataset", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+
+		out := bufio.NewWriter(w)
+		for _, entry := range entries {
+			fmt.Fprintf(out, "%s:%d\r\n", entry.Suffix, entry.Count)
+		}
+		out.Flush()
+	})
+
+	return mux
+}
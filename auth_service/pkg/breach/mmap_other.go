@@ -0,0 +1,13 @@
+//go:build !unix
+
+package breach
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile falls back to positioned reads where mmap is not available.
+func mapFile(f *os.File, size int64) (io.ReaderAt, func() error, error) {
+	return f, f.Close, nil
+}
@@ -0,0 +1,26 @@
+//go:build unix
+
+package breach
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"syscall"
+)
+
+// mapFile maps the whole file read-only. The mapping outlives f, which is
+// closed right away.
+func mapFile(f *os.File, size int64) (io.ReaderAt, func() error, error) {
+	if size == 0 {
+		return bytes.NewReader(nil), f.Close, nil
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	f.Close()
+
+	return bytes.NewReader(data), func() error { return syscall.Munmap(data) }, nil
+}
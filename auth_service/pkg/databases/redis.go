@@ -0,0 +1,23 @@
+package databases
+
+import (
+	"context"
+
+	"github.com/helyus1412/auth-service/config"
+	"github.com/redis/go-redis/v9"
+)
+
+func InitRedis(ctx context.Context) (*redis.Client, error) {
+	opts, err := redis.ParseURL(config.GlobalEnv.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
@@ -0,0 +1,41 @@
+package denylist
+
+import (
+	"context"
+	"time"
+
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Denylist holds the IDs (jti) of revoked access tokens until the tokens would
+// have expired anyway.
+type Denylist interface {
+	Add(ctx context.Context, jti string, expiresAt time.Time) error
+	Contains(ctx context.Context, jti string) (bool, error)
+	// Purge drops entries whose tokens have expired and returns how many.
+	Purge(ctx context.Context) (int64, error)
+}
+
+// RunCleanup purges expired entries every interval until ctx is done.
+func RunCleanup(ctx context.Context, list Denylist, interval time.Duration, logger *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := list.Purge(ctx)
+			if err != nil {
+				logger.Error(ctx, "denylist.RunCleanup", "Purge", "failed to purge expired denylist entries", err)
+				continue
+			}
+
+			if purged > 0 {
+				logger.Info(ctx, "denylist.RunCleanup", "Purge", "purged expired denylist entries", zap.Int64("purged", purged))
+			}
+		}
+	}
+}
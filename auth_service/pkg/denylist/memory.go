@@ -0,0 +1,53 @@
+package denylist
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryDenylist struct {
+	mu      sync.RWMutex
+	entries map[string]time.Time
+}
+
+// NewMemory keeps the denylist in process. Revocations are not seen by other
+// replicas and are lost on restart.
+func NewMemory() Denylist {
+	return &memoryDenylist{entries: map[string]time.Time{}}
+}
+
+func (d *memoryDenylist) Add(ctx context.Context, jti string, expiresAt time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.entries[jti] = expiresAt
+
+	return nil
+}
+
+func (d *memoryDenylist) Contains(ctx context.Context, jti string) (bool, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	expiresAt, ok := d.entries[jti]
+
+	return ok && time.Now().Before(expiresAt), nil
+}
+
+func (d *memoryDenylist) Purge(ctx context.Context) (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var purged int64
+	now := time.Now()
+
+	for jti, expiresAt := range d.entries {
+		if !now.Before(expiresAt) {
+			delete(d.entries, jti)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,68 @@
+package denylist
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type postgresDenylist struct {
+	db     *sqlx.DB
+	schema string
+}
+
+// NewPostgres keeps the denylist in the revoked_tokens table, shared by all
+// replicas.
+func NewPostgres(db *sqlx.DB, schema string) Denylist {
+	if schema == "" {
+		schema = "public"
+	}
+
+	return &postgresDenylist{db, schema}
+}
+
+func (d *postgresDenylist) Add(ctx context.Context, jti string, expiresAt time.Time) error {
+	queryPrep, err := d.db.PreparexContext(ctx, fmt.Sprintf(`INSERT INTO %s.revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING`, d.schema))
+	if err != nil {
+		return err
+	}
+	defer queryPrep.Close()
+
+	_, err = queryPrep.ExecContext(ctx, jti, expiresAt)
+
+	return err
+}
+
+func (d *postgresDenylist) Contains(ctx context.Context, jti string) (bool, error) {
+	var exists bool
+
+	query, err := d.db.PreparexContext(ctx, fmt.Sprintf(`SELECT EXISTS (SELECT 1 from %s.revoked_tokens where jti = $1 and expires_at > $2)`, d.schema))
+	if err != nil {
+		return false, err
+	}
+	defer query.Close()
+
+	err = query.GetContext(ctx, &exists, jti, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (d *postgresDenylist) Purge(ctx context.Context) (int64, error) {
+	queryPrep, err := d.db.PreparexContext(ctx, fmt.Sprintf(`DELETE FROM %s.revoked_tokens where expires_at <= $1`, d.schema))
+	if err != nil {
+		return 0, err
+	}
+	defer queryPrep.Close()
+
+	res, err := queryPrep.ExecContext(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
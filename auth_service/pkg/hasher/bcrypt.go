@@ -0,0 +1,79 @@
+package hasher
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcrypt only looks at the first 72 bytes of a password
+const bcryptMaxPasswordLength = 72
+
+type BcryptParams struct {
+	Cost int
+}
+
+type bcryptHasher struct {
+	params BcryptParams
+}
+
+// NewBcrypt hashes into the modular crypt format "$2a$<cost>$<salt+hash>".
+// Passwords longer than 72 bytes are refused rather than truncated.
+func NewBcrypt(params BcryptParams) PasswordHasher {
+	if params.Cost == 0 {
+		params.Cost = bcrypt.DefaultCost
+	}
+
+	return &bcryptHasher{params}
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	if password == "" {
+		return "", ErrEmptyPassword
+	}
+
+	if len(password) > bcryptMaxPasswordLength {
+		return "", ErrPasswordTooLong
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.params.Cost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hashed), nil
+}
+
+func (h *bcryptHasher) Verify(encoded string, password string) (bool, error) {
+	if !h.Supports(encoded) {
+		return false, ErrUnsupportedHash
+	}
+
+	if err := checkBcryptCost(encoded); err != nil {
+		return false, err
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+
+	return true, nil
+}
+
+func (h *bcryptHasher) Supports(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (h *bcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return true
+	}
+
+	return cost < h.params.Cost
+}
@@ -0,0 +1,49 @@
+package hasher
+
+import (
+	"fmt"
+)
+
+// Config selects the algorithm new passwords are hashed with. Firebase hashes
+// are only verified when Firebase.SignerKey is set.
+type Config struct {
+	// Algorithm is "argon2id" or "bcrypt".
+	Algorithm string
+	Bcrypt    BcryptParams
+	Argon2id  Argon2idParams
+	Firebase  FirebaseScryptParams
+}
+
+// NewFromConfig hashes with the configured algorithm. Hashes of the other
+// algorithm and of imported formats are still verified and upgraded on the
+// next login.
+func NewFromConfig(cfg Config) (PasswordHasher, error) {
+	// hashes above the limits fail to verify, the own ones must not
+	if err := cfg.Argon2id.checkCost(); err != nil {
+		return nil, fmt.Errorf("hasher: argon2 parameters exceed the limits: %w", err)
+	}
+	if err := cfg.Bcrypt.checkCost(); err != nil {
+		return nil, fmt.Errorf("hasher: bcrypt cost exceeds the limit: %w", err)
+	}
+
+	bcryptHasher := NewBcrypt(cfg.Bcrypt)
+	argon2idHasher := NewArgon2id(cfg.Argon2id)
+
+	current, legacy := argon2idHasher, []PasswordHasher{bcryptHasher}
+	if cfg.Algorithm == "bcrypt" {
+		current, legacy = bcryptHasher, []PasswordHasher{argon2idHasher}
+	}
+
+	legacy = append(legacy, NewPBKDF2(), NewDjango(), NewScrypt())
+
+	if cfg.Firebase.SignerKey != "" {
+		firebase, err := NewFirebaseScrypt(cfg.Firebase)
+		if err != nil {
+			return nil, err
+		}
+
+		legacy = append(legacy, firebase)
+	}
+
+	return New(current, legacy...), nil
+}
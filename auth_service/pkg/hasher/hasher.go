@@ -0,0 +1,76 @@
+package hasher
+
+import (
+	"errors"
+)
+
+var (
+	ErrEmptyPassword   = errors.New("hasher: password is empty")
+	ErrPasswordTooLong = errors.New("hasher: password is too long")
+	ErrUnsupportedHash = errors.New("hasher: unsupported hash format")
+	ErrMalformedHash   = errors.New("hasher: malformed hash")
+	// ErrVerifyOnly is returned by hashers of imported formats, which can
+	// verify existing hashes but never produce new ones.
+	ErrVerifyOnly = errors.New("hasher: algorithm only supports verification")
+)
+
+// PasswordHasher hashes passwords into self-describing strings and verifies
+// passwords against them.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Verify reports whether password matches encoded. It returns
+	// ErrUnsupportedHash when encoded was produced by another algorithm.
+	Verify(encoded string, password string) (bool, error)
+	// Supports reports whether encoded was produced by this algorithm.
+	Supports(encoded string) bool
+	// NeedsRehash reports whether encoded should be replaced by a fresh hash
+	// because it uses another algorithm or weaker parameters.
+	NeedsRehash(encoded string) bool
+}
+
+type chain struct {
+	current PasswordHasher
+	legacy  []PasswordHasher
+}
+
+// New hashes with current and verifies hashes of current or any of legacy.
+// Hashes not produced by current with its present parameters need a rehash.
+func New(current PasswordHasher, legacy ...PasswordHasher) PasswordHasher {
+	return &chain{current, legacy}
+}
+
+func (c *chain) Hash(password string) (string, error) {
+	return c.current.Hash(password)
+}
+
+func (c *chain) Verify(encoded string, password string) (bool, error) {
+	if c.current.Supports(encoded) {
+		return c.current.Verify(encoded, password)
+	}
+
+	for _, h := range c.legacy {
+		if h.Supports(encoded) {
+			return h.Verify(encoded, password)
+		}
+	}
+
+	return false, ErrUnsupportedHash
+}
+
+func (c *chain) Supports(encoded string) bool {
+	if c.current.Supports(encoded) {
+		return true
+	}
+
+	for _, h := range c.legacy {
+		if h.Supports(encoded) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *chain) NeedsRehash(encoded string) bool {
+	return !c.current.Supports(encoded) || c.current.NeedsRehash(encoded)
+}
@@ -0,0 +1,77 @@
+package hasher
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Upper bounds on the parameters of stored hashes. Every login verifies the
+// hash with its own parameters, so without them a single imported hash could
+// make each attempt allocate gigabytes or run for minutes.
+const (
+	// MaxArgon2idMemory is in KiB, 256 MiB.
+	MaxArgon2idMemory      = 256 * 1024
+	MaxArgon2idIterations  = 16
+	MaxArgon2idParallelism = 16
+	// MaxScryptMemory bounds 128 * N * r * p, the bytes scrypt allocates.
+	MaxScryptMemory = 256 << 20
+	MaxPBKDF2Rounds = 5_000_000
+	// MaxBcryptCost is a log2 of rounds, each step doubles the work.
+	MaxBcryptCost = 14
+	// MaxKeyLength bounds the derived key of every format.
+	MaxKeyLength = 128
+)
+
+var ErrCostTooHigh = errors.New("hasher: hash parameters exceed the allowed cost")
+
+// CheckCost returns ErrCostTooHigh or ErrMalformedHash for a hash Verify
+// would refuse because of its parameters, without deriving any key. Formats
+// whose cost is not part of the hash, and unknown formats, pass.
+func CheckCost(encoded string) error {
+	var err error
+
+	switch {
+	case strings.HasPrefix(encoded, argon2idPrefix):
+		_, _, _, err = decodeArgon2id(encoded)
+	case strings.HasPrefix(encoded, djangoArgon2Prefix):
+		_, _, _, err = decodeArgon2id(strings.TrimPrefix(encoded, "argon2"))
+	case strings.HasPrefix(encoded, scryptPrefix):
+		_, err = decodeScrypt(encoded)
+	case (&bcryptHasher{}).Supports(encoded):
+		err = checkBcryptCost(encoded)
+	case strings.HasPrefix(encoded, pbkdf2Prefix):
+		_, _, _, err = decodePBKDF2(encoded)
+	case strings.HasPrefix(encoded, djangoPBKDF2Prefix):
+		_, _, _, err = decodeDjangoPBKDF2(encoded)
+	}
+
+	return err
+}
+
+func (p Argon2idParams) checkCost() error {
+	if p.Memory > MaxArgon2idMemory || p.Iterations > MaxArgon2idIterations ||
+		p.Parallelism > MaxArgon2idParallelism || p.KeyLength > MaxKeyLength {
+		return ErrCostTooHigh
+	}
+
+	return nil
+}
+
+func (p BcryptParams) checkCost() error {
+	if p.Cost > MaxBcryptCost {
+		return ErrCostTooHigh
+	}
+
+	return nil
+}
+
+func checkBcryptCost(encoded string) error {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return ErrMalformedHash
+	}
+
+	return BcryptParams{Cost: cost}.checkCost()
+}
@@ -0,0 +1,86 @@
+package hasher
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestCheckCost(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    error
+	}{
+		{"argon2id default", "$argon2id$v=19$m=65536,t=3,p=2$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", nil},
+		{"argon2id at limit", "$argon2id$v=19$m=262144,t=16,p=16$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", nil},
+		{"argon2id memory", "$argon2id$v=19$m=262145,t=3,p=2$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", ErrCostTooHigh},
+		{"argon2id iterations", "$argon2id$v=19$m=65536,t=17,p=2$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", ErrCostTooHigh},
+		{"argon2id parallelism", "$argon2id$v=19$m=65536,t=3,p=17$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", ErrCostTooHigh},
+		{"django argon2 memory", "argon2$argon2id$v=19$m=4194304,t=3,p=2$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", ErrCostTooHigh},
+		{"argon2id malformed", "$argon2id$v=19$m=0,t=3,p=2$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", ErrMalformedHash},
+		{"scrypt", "$scrypt$ln=14,r=8,p=1$c2FsdA$c2FsdA", nil},
+		// 128 * 2^18 * 8 * 1 is 256 MiB
+		{"scrypt at limit", "$scrypt$ln=18,r=8,p=1$c2FsdA$c2FsdA", nil},
+		{"scrypt N", "$scrypt$ln=20,r=8,p=1$c2FsdA$c2FsdA", ErrCostTooHigh},
+		{"scrypt p", "$scrypt$ln=14,r=8,p=1024$c2FsdA$c2FsdA", ErrCostTooHigh},
+		{"scrypt r overflow", "$scrypt$ln=1,r=9223372036854775807,p=9223372036854775807$c2FsdA$c2FsdA", ErrCostTooHigh},
+		{"scrypt ln out of range", "$scrypt$ln=64,r=8,p=1$c2FsdA$c2FsdA", ErrMalformedHash},
+		{"pbkdf2", "$pbkdf2-sha256$i=600000$c2FsdA$c2FsdA", nil},
+		{"pbkdf2 rounds", "$pbkdf2-sha256$i=5000001$c2FsdA$c2FsdA", ErrCostTooHigh},
+		{"django pbkdf2 rounds", "pbkdf2_sha256$99999999$seasalt$c2FsdA==", ErrCostTooHigh},
+		{"key length", "$pbkdf2-sha256$i=1$c2FsdA$" + base64.RawStdEncoding.EncodeToString(make([]byte, MaxKeyLength+1)), ErrCostTooHigh},
+		{"bcrypt", "$2a$04$ac205pmmFRmbQc6lacPVfes.v9g3ZF4dyEL2XKMIAFj.MBW8vGmbe", nil},
+		{"bcrypt at limit", "$2b$14$ac205pmmFRmbQc6lacPVfes.v9g3ZF4dyEL2XKMIAFj.MBW8vGmbe", nil},
+		{"bcrypt cost", "$2a$31$ac205pmmFRmbQc6lacPVfes.v9g3ZF4dyEL2XKMIAFj.MBW8vGmbe", ErrCostTooHigh},
+		{"bcrypt malformed", "$2a$31$abcdefghijklmnopqrstuu", ErrMalformedHash},
+		// unknown formats are refused elsewhere
+		{"unknown", "md5$abc", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckCost(tt.encoded); !errors.Is(err, tt.want) {
+				t.Fatalf("CheckCost() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRefusesCostlyHash(t *testing.T) {
+	// must fail before any key is derived, deriving would take minutes
+	tests := []struct {
+		name    string
+		h       PasswordHasher
+		encoded string
+	}{
+		{"argon2id", NewArgon2id(Argon2idParams{}), "$argon2id$v=19$m=4194304,t=1000,p=2$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+		{"bcrypt", NewBcrypt(BcryptParams{}), "$2a$31$ac205pmmFRmbQc6lacPVfes.v9g3ZF4dyEL2XKMIAFj.MBW8vGmbe"},
+		{"scrypt", NewScrypt(), "$scrypt$ln=30,r=8,p=1$c2FsdA$c2FsdA"},
+		{"pbkdf2", NewPBKDF2(), "$pbkdf2-sha256$i=2000000000$c2FsdA$c2FsdA"},
+		{"django", NewDjango(), "pbkdf2_sha256$2000000000$seasalt$c2FsdA=="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := tt.h.Verify(tt.encoded, "password")
+			if ok || !errors.Is(err, ErrCostTooHigh) {
+				t.Fatalf("Verify() = %v, %v, want ErrCostTooHigh", ok, err)
+			}
+		})
+	}
+}
+
+func TestNewFromConfigRefusesCostlyArgon2id(t *testing.T) {
+	_, err := NewFromConfig(Config{Algorithm: "argon2id", Argon2id: Argon2idParams{Memory: MaxArgon2idMemory + 1}})
+	if !errors.Is(err, ErrCostTooHigh) {
+		t.Fatalf("NewFromConfig() error = %v, want ErrCostTooHigh", err)
+	}
+}
+
+func TestNewFromConfigRefusesCostlyBcrypt(t *testing.T) {
+	_, err := NewFromConfig(Config{Algorithm: "bcrypt", Bcrypt: BcryptParams{Cost: MaxBcryptCost + 1}})
+	if !errors.Is(err, ErrCostTooHigh) {
+		t.Fatalf("NewFromConfig() error = %v, want ErrCostTooHigh", err)
+	}
+}
@@ -0,0 +1,184 @@
+package hasher
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+const (
+	pbkdf2Prefix       = "$pbkdf2-sha256$"
+	djangoPBKDF2Prefix = "pbkdf2_sha256$"
+	djangoArgon2Prefix = "argon2$argon2id$"
+)
+
+type pbkdf2Hasher struct{}
+
+// NewPBKDF2 verifies imported PBKDF2-SHA256 hashes in the PHC form
+// "$pbkdf2-sha256$i=<iterations>,l=<length>$<salt>$<hash>" or the passlib form
+// "$pbkdf2-sha256$<iterations>$<salt>$<hash>". It cannot hash new passwords.
+func NewPBKDF2() PasswordHasher {
+	return &pbkdf2Hasher{}
+}
+
+func (h *pbkdf2Hasher) Hash(password string) (string, error) {
+	return "", ErrVerifyOnly
+}
+
+func (h *pbkdf2Hasher) Verify(encoded string, password string) (bool, error) {
+	if !h.Supports(encoded) {
+		return false, ErrUnsupportedHash
+	}
+
+	iterations, salt, key, err := decodePBKDF2(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	actual, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(key))
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+func (h *pbkdf2Hasher) Supports(encoded string) bool {
+	return strings.HasPrefix(encoded, pbkdf2Prefix)
+}
+
+func (h *pbkdf2Hasher) NeedsRehash(encoded string) bool {
+	return true
+}
+
+func decodePBKDF2(encoded string) (int, []byte, []byte, error) {
+	// "", "pbkdf2-sha256", params, salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 {
+		return 0, nil, nil, ErrMalformedHash
+	}
+
+	iterations, err := pbkdf2Iterations(parts[2])
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	salt, err := decodeAdaptedBase64(parts[3])
+	if err != nil {
+		return 0, nil, nil, ErrMalformedHash
+	}
+
+	key, err := decodeAdaptedBase64(parts[4])
+	if err != nil || len(key) == 0 {
+		return 0, nil, nil, ErrMalformedHash
+	}
+
+	if iterations > MaxPBKDF2Rounds || len(key) > MaxKeyLength {
+		return 0, nil, nil, ErrCostTooHigh
+	}
+
+	return iterations, salt, key, nil
+}
+
+// pbkdf2Iterations reads "i=<n>[,l=<n>]" or a bare iteration count.
+func pbkdf2Iterations(params string) (int, error) {
+	for _, param := range strings.Split(params, ",") {
+		name, value, found := strings.Cut(param, "=")
+		if !found {
+			value = name
+		} else if name != "i" {
+			continue
+		}
+
+		iterations, err := strconv.Atoi(value)
+		if err != nil || iterations < 1 {
+			return 0, ErrMalformedHash
+		}
+
+		return iterations, nil
+	}
+
+	return 0, ErrMalformedHash
+}
+
+// decodeAdaptedBase64 accepts standard base64 with or without padding and
+// passlib's variant that uses "." in place of "+".
+func decodeAdaptedBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.ReplaceAll(s, ".", "+"), "=")
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+type djangoHasher struct {
+	argon2id PasswordHasher
+}
+
+// NewDjango verifies hashes exported from Django's auth_user table, either
+// "pbkdf2_sha256$<iterations>$<salt>$<hash>" or Django's argon2 variant
+// "argon2$argon2id$v=19$m=..,t=..,p=..$<salt>$<hash>". Django's bcrypt
+// hashers pre-hash the password and are not supported. It cannot hash new
+// passwords.
+func NewDjango() PasswordHasher {
+	return &djangoHasher{NewArgon2id(Argon2idParams{})}
+}
+
+func (h *djangoHasher) Hash(password string) (string, error) {
+	return "", ErrVerifyOnly
+}
+
+func (h *djangoHasher) Verify(encoded string, password string) (bool, error) {
+	if strings.HasPrefix(encoded, djangoArgon2Prefix) {
+		return h.argon2id.Verify(strings.TrimPrefix(encoded, "argon2"), password)
+	}
+
+	if !strings.HasPrefix(encoded, djangoPBKDF2Prefix) {
+		return false, ErrUnsupportedHash
+	}
+
+	iterations, salt, key, err := decodeDjangoPBKDF2(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	actual, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(key))
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+func decodeDjangoPBKDF2(encoded string) (int, []byte, []byte, error) {
+	// "pbkdf2_sha256", iterations, salt, hash
+	parts := strings.SplitN(encoded, "$", 4)
+	if len(parts) != 4 {
+		return 0, nil, nil, ErrMalformedHash
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return 0, nil, nil, ErrMalformedHash
+	}
+
+	key, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil || len(key) == 0 {
+		return 0, nil, nil, ErrMalformedHash
+	}
+
+	if iterations > MaxPBKDF2Rounds || len(key) > MaxKeyLength {
+		return 0, nil, nil, ErrCostTooHigh
+	}
+
+	// Django uses the salt string as is, it is not encoded
+	return iterations, []byte(parts[2]), key, nil
+}
+
+func (h *djangoHasher) Supports(encoded string) bool {
+	return strings.HasPrefix(encoded, djangoPBKDF2Prefix) || strings.HasPrefix(encoded, djangoArgon2Prefix)
+}
+
+func (h *djangoHasher) NeedsRehash(encoded string) bool {
+	return true
+}
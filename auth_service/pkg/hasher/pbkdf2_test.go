@@ -0,0 +1,105 @@
+package hasher
+
+import (
+	"errors"
+	"testing"
+)
+
+// verifyTest checks one encoded hash against a correct and a wrong password.
+type verifyTest struct {
+	name     string
+	encoded  string
+	password string
+}
+
+func runVerifyTests(t *testing.T, h PasswordHasher, tests []verifyTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !h.Supports(tt.encoded) {
+				t.Fatalf("Supports(%q) = false", tt.encoded)
+			}
+
+			ok, err := h.Verify(tt.encoded, tt.password)
+			if err != nil || !ok {
+				t.Fatalf("Verify() = %v, %v, want true", ok, err)
+			}
+
+			ok, err = h.Verify(tt.encoded, tt.password+"x")
+			if err != nil || ok {
+				t.Fatalf("Verify() with a wrong password = %v, %v, want false", ok, err)
+			}
+
+			if _, err := h.Hash(tt.password); !errors.Is(err, ErrVerifyOnly) {
+				t.Fatalf("Hash() error = %v, want ErrVerifyOnly", err)
+			}
+
+			if !h.NeedsRehash(tt.encoded) {
+				t.Fatal("NeedsRehash() = false for an imported hash")
+			}
+		})
+	}
+}
+
+func TestPBKDF2Verify(t *testing.T) {
+	// RFC 7914 section 11 PBKDF2-HMAC-SHA256 vectors
+	runVerifyTests(t, NewPBKDF2(), []verifyTest{
+		{
+			name:     "phc form",
+			encoded:  "$pbkdf2-sha256$i=1,l=64$c2FsdA$VawEblbjCJ/sFpHCJUS2BflBhSFt3gRl5oudV8INrLxJypzM8Xm2RZkWZLOdd+8xfHG4RbHjC9UJESBB06GXgw",
+			password: "passwd",
+		},
+		{
+			name:     "passlib form",
+			encoded:  "$pbkdf2-sha256$80000$TmFDbA$TdzY9guYviGDDO5e8icB.WQaRBjQTAQUrv8Ih2s0q1ah1CWhIlgzVJrbhBtRybMXaicr3ruh0HhHj2Kzl/M8jQ",
+			password: "Password",
+		},
+	})
+}
+
+func TestDjangoVerify(t *testing.T) {
+	runVerifyTests(t, NewDjango(), []verifyTest{
+		// from Django's test_hashers
+		{
+			name:     "pbkdf2_sha256",
+			encoded:  "pbkdf2_sha256$10000$seasalt$CWWFdHOWwPnki7HvkcqN9iA2T3KLW1cf2uZ5kvArtVY=",
+			password: "lètmein",
+		},
+		{
+			name:     "pbkdf2_sha256 260000 iterations",
+			encoded:  "pbkdf2_sha256$260000$seasalt2$UCGMhrOoaq1ghQPArIBK5RkI6IZLRxlIwHWA1dMy7y8=",
+			password: "lètmein",
+		},
+		// argon2id reference implementation vector in Django's encoding
+		{
+			name:     "argon2",
+			encoded:  "argon2$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+			password: "password",
+		},
+	})
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		h       PasswordHasher
+		encoded string
+	}{
+		{"pbkdf2 missing hash", NewPBKDF2(), "$pbkdf2-sha256$i=1$c2FsdA"},
+		{"pbkdf2 zero iterations", NewPBKDF2(), "$pbkdf2-sha256$i=0$c2FsdA$VawEblbjCJ8"},
+		{"pbkdf2 bad base64", NewPBKDF2(), "$pbkdf2-sha256$i=1$c2FsdA$!!!"},
+		{"django missing hash", NewDjango(), "pbkdf2_sha256$10000$seasalt"},
+		{"django bad iterations", NewDjango(), "pbkdf2_sha256$ten$seasalt$CWWFdHOWwPnki7HvkcqN9iA2T3KLW1cf2uZ5kvArtVY="},
+		{"scrypt missing params", NewScrypt(), "$scrypt$ln=10$TmFDbA$/bq+HJ00cgB4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := tt.h.Verify(tt.encoded, "password")
+			if ok || !errors.Is(err, ErrMalformedHash) {
+				t.Fatalf("Verify() = %v, %v, want ErrMalformedHash", ok, err)
+			}
+		})
+	}
+}
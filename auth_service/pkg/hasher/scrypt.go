@@ -0,0 +1,189 @@
+package hasher
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	scryptPrefix         = "$scrypt$"
+	firebaseScryptPrefix = "$firebase-scrypt$"
+)
+
+type scryptHasher struct{}
+
+// NewScrypt verifies imported scrypt hashes in the PHC form
+// "$scrypt$ln=<log2 N>,r=<r>,p=<p>$<salt>$<hash>". It cannot hash new passwords.
+func NewScrypt() PasswordHasher {
+	return &scryptHasher{}
+}
+
+func (h *scryptHasher) Hash(password string) (string, error) {
+	return "", ErrVerifyOnly
+}
+
+func (h *scryptHasher) Verify(encoded string, password string) (bool, error) {
+	if !h.Supports(encoded) {
+		return false, ErrUnsupportedHash
+	}
+
+	params, err := decodeScrypt(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	actual, err := scrypt.Key([]byte(password), params.salt, 1<<params.logN, params.r, params.p, len(params.key))
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+
+	return subtle.ConstantTimeCompare(actual, params.key) == 1, nil
+}
+
+type scryptParams struct {
+	logN, r, p int
+	salt, key  []byte
+}
+
+func decodeScrypt(encoded string) (scryptParams, error) {
+	var params scryptParams
+
+	// "", "scrypt", params, salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 {
+		return params, ErrMalformedHash
+	}
+
+	_, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &params.logN, &params.r, &params.p)
+	if err != nil || params.logN < 1 || params.logN > 30 || params.r < 1 || params.p < 1 {
+		return params, ErrMalformedHash
+	}
+
+	// dividing keeps the product from overflowing
+	if params.r > MaxScryptMemory/128/params.p || 1<<params.logN > MaxScryptMemory/128/params.r/params.p {
+		return params, ErrCostTooHigh
+	}
+
+	params.salt, err = decodeAdaptedBase64(parts[3])
+	if err != nil {
+		return params, ErrMalformedHash
+	}
+
+	params.key, err = decodeAdaptedBase64(parts[4])
+	if err != nil || len(params.key) == 0 {
+		return params, ErrMalformedHash
+	}
+
+	if len(params.key) > MaxKeyLength {
+		return params, ErrCostTooHigh
+	}
+
+	return params, nil
+}
+
+func (h *scryptHasher) Supports(encoded string) bool {
+	return strings.HasPrefix(encoded, scryptPrefix)
+}
+
+func (h *scryptHasher) NeedsRehash(encoded string) bool {
+	return true
+}
+
+// FirebaseScryptParams are the project-wide hash parameters shown in the
+// Firebase console under "Password hash parameters". Keys are base64 encoded
+// as shown there.
+type FirebaseScryptParams struct {
+	SignerKey     string
+	SaltSeparator string
+	Rounds        int
+	MemCost       int
+}
+
+type firebaseScryptHasher struct {
+	signerKey     []byte
+	saltSeparator []byte
+	rounds        int
+	memCost       int
+}
+
+// NewFirebaseScrypt verifies hashes exported from Firebase Authentication,
+// stored as "$firebase-scrypt$<salt>$<hash>" with the base64 salt and hash of
+// the export. It cannot hash new passwords.
+func NewFirebaseScrypt(params FirebaseScryptParams) (PasswordHasher, error) {
+	signerKey, err := base64.StdEncoding.DecodeString(params.SignerKey)
+	if err != nil || len(signerKey) == 0 {
+		return nil, fmt.Errorf("hasher: invalid firebase signer key")
+	}
+
+	saltSeparator, err := base64.StdEncoding.DecodeString(params.SaltSeparator)
+	if err != nil {
+		return nil, fmt.Errorf("hasher: invalid firebase salt separator")
+	}
+
+	if params.Rounds < 1 || params.Rounds > 8 || params.MemCost < 1 || params.MemCost > 14 {
+		return nil, fmt.Errorf("hasher: invalid firebase rounds or mem cost")
+	}
+
+	return &firebaseScryptHasher{signerKey, saltSeparator, params.Rounds, params.MemCost}, nil
+}
+
+// FirebaseScryptHash builds the stored form of a hash and salt exported from Firebase.
+func FirebaseScryptHash(salt string, hash string) string {
+	return firebaseScryptPrefix + salt + "$" + hash
+}
+
+func (h *firebaseScryptHasher) Hash(password string) (string, error) {
+	return "", ErrVerifyOnly
+}
+
+func (h *firebaseScryptHasher) Verify(encoded string, password string) (bool, error) {
+	if !h.Supports(encoded) {
+		return false, ErrUnsupportedHash
+	}
+
+	salt64, hash64, found := strings.Cut(strings.TrimPrefix(encoded, firebaseScryptPrefix), "$")
+	if !found {
+		return false, ErrMalformedHash
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(salt64)
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+
+	expected, err := base64.StdEncoding.DecodeString(hash64)
+	if err != nil || len(expected) == 0 {
+		return false, ErrMalformedHash
+	}
+
+	derived, err := scrypt.Key([]byte(password), append(salt, h.saltSeparator...), 1<<h.memCost, h.rounds, 1, 32)
+	if err != nil {
+		return false, err
+	}
+
+	// the hash is the signer key encrypted with AES-256-CTR under the derived
+	// key and a zero IV
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return false, err
+	}
+
+	actual := make([]byte, len(h.signerKey))
+	cipher.NewCTR(block, make([]byte, aes.BlockSize)).XORKeyStream(actual, h.signerKey)
+
+	return subtle.ConstantTimeCompare(actual, expected) == 1, nil
+}
+
+func (h *firebaseScryptHasher) Supports(encoded string) bool {
+	return strings.HasPrefix(encoded, firebaseScryptPrefix)
+}
+
+func (h *firebaseScryptHasher) NeedsRehash(encoded string) bool {
+	return true
+}
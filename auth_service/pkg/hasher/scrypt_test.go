@@ -0,0 +1,69 @@
+package hasher
+
+import (
+	"testing"
+)
+
+func TestScryptVerify(t *testing.T) {
+	// RFC 7914 section 12 scrypt vectors
+	runVerifyTests(t, NewScrypt(), []verifyTest{
+		{
+			name:     "N=1024 r=8 p=16",
+			encoded:  "$scrypt$ln=10,r=8,p=16$TmFDbA$/bq+HJ00cgB4VucZDQHp/nxq18vII3gw53N2Y0s3MWIurzDZLiKjiG/xCSedmDDaxyevuUqD7m2DYMvfoswGQA",
+			password: "password",
+		},
+		{
+			name:     "N=16384 r=8 p=1",
+			encoded:  "$scrypt$ln=14,r=8,p=1$U29kaXVtQ2hsb3JpZGU$cCO9yzr9c0hGHAbNgf046/2o+7qQT44+qbVD9lRdofLVQylVYT8Pz2LUlwUkKpr55h6F3A1lHkDfzwF7RVdYhw",
+			password: "pleaseletmein",
+		},
+	})
+}
+
+// The reference parameters and hash of github.com/firebase/scrypt.
+var firebaseTestParams = FirebaseScryptParams{
+	SignerKey:     "jxspr8Ki0RYycVU8zykbdLGjFQ3McFUH0uiiTvC8pVMXAn210wjLNmdZJzxUECKbm0QsEmYUSDzZvpjeJ9WmXA==",
+	SaltSeparator: "Bw==",
+	Rounds:        8,
+	MemCost:       14,
+}
+
+func TestFirebaseScryptVerify(t *testing.T) {
+	h, err := NewFirebaseScrypt(firebaseTestParams)
+	if err != nil {
+		t.Fatalf("NewFirebaseScrypt() error = %v", err)
+	}
+
+	runVerifyTests(t, h, []verifyTest{
+		{
+			name:     "reference",
+			encoded:  FirebaseScryptHash("42xEC+ixf3L2lw==", "lSrfV15cpx95/sZS2W9c9Kp6i/LVgQNDNC/qzrCnh1SAyZvqmZqAjTdn3aoItz+VHjoZilo78198JAdRuid5lQ=="),
+			password: "user1password",
+		},
+	})
+}
+
+func TestNewFirebaseScryptInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*FirebaseScryptParams)
+	}{
+		{"missing signer key", func(p *FirebaseScryptParams) { p.SignerKey = "" }},
+		{"signer key not base64", func(p *FirebaseScryptParams) { p.SignerKey = "not base64" }},
+		{"salt separator not base64", func(p *FirebaseScryptParams) { p.SaltSeparator = "%" }},
+		{"zero rounds", func(p *FirebaseScryptParams) { p.Rounds = 0 }},
+		{"too many rounds", func(p *FirebaseScryptParams) { p.Rounds = 9 }},
+		{"mem cost too high", func(p *FirebaseScryptParams) { p.MemCost = 15 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := firebaseTestParams
+			tt.modify(&params)
+
+			if _, err := NewFirebaseScrypt(params); err == nil {
+				t.Fatal("NewFirebaseScrypt() error = nil")
+			}
+		})
+	}
+}
@@ -145,3 +145,32 @@ func NewCustomError(code int, customCode, message string, data ...interface{}) C
 
 	return errObj
 }
+
+// FieldError describes a single rule a field failed.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError struct
+type ValidationError struct {
+	Code       int          `json:"code"`
+	CustomCode string       `json:"custom_code"`
+	Message    string       `json:"message"`
+	Data       interface{}  `json:"data"`
+	Errors     []FieldError `json:"errors"`
+}
+
+func NewValidationError(customCode, message string, errors []FieldError) ValidationError {
+	errObj := ValidationError{}
+	errObj.Message = "Validation Failed"
+	if message != "" {
+		errObj.Message = message
+	}
+	errObj.CustomCode = customCode
+	errObj.Code = http.StatusBadRequest
+	errObj.Errors = errors
+
+	return errObj
+}
@@ -0,0 +1,119 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional emails such as password reset links.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+type smtpMailer struct {
+	config SMTPConfig
+}
+
+// NewSMTPMailer sends through an SMTP relay, upgrading to STARTTLS when the
+// server offers it. Authentication is skipped when no username is set.
+func NewSMTPMailer(config SMTPConfig) Mailer {
+	return &smtpMailer{config}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, message Message) error {
+	addr := net.JoinHostPort(m.config.Host, fmt.Sprint(m.config.Port))
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	return smtp.SendMail(addr, auth, m.config.From, []string{message.To}, render(m.config.From, message))
+}
+
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer writes every message as an .eml file into dir, for local
+// development without a mail server.
+func NewFileMailer(dir string, from string) Mailer {
+	return &fileMailer{dir, from}
+}
+
+func (m *fileMailer) Send(ctx context.Context, message Message) error {
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), sanitize(message.To))
+
+	return os.WriteFile(filepath.Join(m.dir, name), render(m.from, message), 0o600)
+}
+
+type logMailer struct {
+	logger *logger.Logger
+}
+
+// NewLogMailer prints messages to the application log instead of sending them.
+func NewLogMailer(logger *logger.Logger) Mailer {
+	return &logMailer{logger}
+}
+
+func (m *logMailer) Send(ctx context.Context, message Message) error {
+	m.logger.Info(ctx, "mailer.Send", "LogMailer", "email not sent, logged instead",
+		zap.String("to", message.To),
+		zap.String("subject", message.Subject),
+		zap.String("body", message.Body),
+	)
+
+	return nil
+}
+
+func render(from string, message Message) []byte {
+	var b strings.Builder
+
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + message.To + "\r\n")
+	b.WriteString("Subject: " + message.Subject + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
+
+func sanitize(address string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, address)
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/helyus1412/auth-service/model"
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/principal"
+	"github.com/helyus1412/auth-service/pkg/token"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"github.com/labstack/echo/v4"
+)
+
+// PrincipalKey is the echo.Context key under which the authenticated principal is stored.
+const PrincipalKey = "principal"
+
+// TokenDenylist reports whether an access token was revoked before its expiry.
+type TokenDenylist interface {
+	Contains(ctx context.Context, jti string) (bool, error)
+}
+
+// Authenticate validates the bearer access token of the request and stores the
+// resulting principal in both the echo context and the request context.
+// Tokens found in the denylist are rejected, as are tokens of users that are
+// no longer active, e.g. suspended after the token was issued.
+func Authenticate(tokenManager *token.Manager, denylist TokenDenylist, statuses UserStatuses) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			raw, ok := bearerToken(c.Request().Header.Get(echo.HeaderAuthorization))
+			if !ok {
+				return utils.ResponseError(httpError.NewUnauthorized("missing bearer token"), c)
+			}
+
+			claims, err := tokenManager.Parse(raw)
+			if err != nil {
+				return utils.ResponseError(httpError.NewUnauthorized("invalid access token"), c)
+			}
+
+			if claims.ID != "" {
+				revoked, err := denylist.Contains(c.Request().Context(), claims.ID)
+				if err != nil {
+					return utils.ResponseError(httpError.NewInternalServerError(err.Error()), c)
+				}
+
+				if revoked {
+					return utils.ResponseError(httpError.NewUnauthorized("access token revoked"), c)
+				}
+			}
+
+			userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+			if err != nil {
+				return utils.ResponseError(httpError.NewUnauthorized("invalid access token"), c)
+			}
+
+			status, err := statuses.Status(c.Request().Context(), userID)
+			if err != nil {
+				return utils.ResponseError(httpError.NewInternalServerError(err.Error()), c)
+			}
+
+			if status != model.UserStatusActive {
+				return utils.ResponseError(httpError.NewUnauthorized("account is not active"), c)
+			}
+
+			p := &principal.Principal{
+				UserID:    userID,
+				Email:     claims.Email,
+				Roles:     claims.Roles,
+				Scope:     claims.Scope,
+				ClientID:  claims.ClientID,
+				AMR:       claims.AMR,
+				SessionID: claims.SessionID,
+				TokenID:   claims.ID,
+				ExpiresAt: claims.ExpiresAt.Time,
+			}
+
+			c.Set(PrincipalKey, p)
+			c.SetRequest(c.Request().WithContext(principal.NewContext(c.Request().Context(), p)))
+
+			return next(c)
+		}
+	}
+}
+
+// FirstParty rejects access tokens issued to OAuth clients. They carry a
+// client_id and are meant for resource servers and /userinfo, not for managing
+// the account they were issued for. It must be chained after Authenticate.
+func FirstParty() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			p, ok := principal.FromContext(c.Request().Context())
+			if !ok {
+				return utils.ResponseError(httpError.NewUnauthorized(""), c)
+			}
+
+			if p.ClientID != "" {
+				return utils.ResponseError(httpError.NewForbidden("tokens issued to OAuth clients are not accepted here"), c)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, raw, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	raw = strings.TrimSpace(raw)
+
+	return raw, raw != ""
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/principal"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"github.com/labstack/echo/v4"
+)
+
+// RequireMFA rejects principals holding any of the given roles unless their
+// token was obtained with a second factor. It must be chained after Authenticate.
+func RequireMFA(roles []string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			p, ok := principal.FromContext(c.Request().Context())
+			if !ok {
+				return utils.ResponseError(httpError.NewUnauthorized(""), c)
+			}
+
+			if p.HasAMR("mfa") {
+				return next(c)
+			}
+
+			for _, role := range roles {
+				if p.HasRole(role) {
+					return utils.ResponseError(httpError.NewCustomError(http.StatusForbidden, "MFA-REQUIRED",
+						"multi-factor authentication is required for this account, enroll an authenticator and sign in again"), c)
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/principal"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"github.com/labstack/echo/v4"
+)
+
+// PermissionChecker reports whether a user has been granted a permission.
+type PermissionChecker interface {
+	HasPermission(userID int64, permission string) (bool, error)
+}
+
+// RequirePermission rejects requests whose principal lacks the given permission.
+// It must be chained after Authenticate.
+func RequirePermission(checker PermissionChecker, permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			p, ok := principal.FromContext(c.Request().Context())
+			if !ok {
+				return utils.ResponseError(httpError.NewUnauthorized(""), c)
+			}
+
+			allowed, err := checker.HasPermission(p.UserID, permission)
+			if err != nil {
+				return utils.ResponseError(httpError.NewInternalServerError(err.Error()), c)
+			}
+
+			if !allowed {
+				return utils.ResponseError(httpError.NewForbidden("missing permission "+permission), c)
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	httpError "github.com/helyus1412/auth-service/pkg/httpError"
+	"github.com/helyus1412/auth-service/pkg/logger"
+	"github.com/helyus1412/auth-service/pkg/principal"
+	"github.com/helyus1412/auth-service/pkg/ratelimit"
+	"github.com/helyus1412/auth-service/pkg/utils"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// KeyFunc derives the bucket a request is counted against.
+type KeyFunc func(c echo.Context) string
+
+// KeyByIP counts requests per client IP.
+func KeyByIP(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
+
+// KeyByUser counts requests per authenticated user, falling back to the client
+// IP for anonymous requests. It must be chained after Authenticate.
+func KeyByUser(c echo.Context) string {
+	if p, ok := principal.FromContext(c.Request().Context()); ok {
+		return "user:" + strconv.FormatInt(p.UserID, 10)
+	}
+
+	return KeyByIP(c)
+}
+
+// KeyByRoute counts every request to the route together, regardless of caller.
+func KeyByRoute(c echo.Context) string {
+	return "route:" + c.Request().Method + " " + c.Path()
+}
+
+type RateLimitConfig struct {
+	// Name separates the buckets of routes sharing a key function.
+	Name  string
+	Limit ratelimit.Limit
+	Key   KeyFunc
+}
+
+// RateLimit rejects requests over the configured limit with 429. The
+// RateLimit-* headers are set on every response. Requests are let through
+// when the store is unavailable.
+func RateLimit(store ratelimit.Store, log *logger.Logger, config RateLimitConfig) echo.MiddlewareFunc {
+	if config.Key == nil {
+		config.Key = KeyByIP
+	}
+
+	if config.Limit.Burst == 0 {
+		config.Limit.Burst = config.Limit.Requests
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			key := config.Name + ":" + config.Key(c)
+
+			res, err := store.Take(ctx, key, config.Limit)
+			if err != nil {
+				log.Error(ctx, "middleware.RateLimit", "Take", "rate limit store unavailable, allowing request", err,
+					zap.String("key", key))
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			header.Set("RateLimit-Reset", seconds(res.Reset))
+
+			if !res.Allowed {
+				header.Set("Retry-After", seconds(res.RetryAfter))
+
+				return utils.ResponseError(httpError.NewCustomError(http.StatusTooManyRequests, "RATE-LIMITED",
+					"too many requests, try again later"), c)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// seconds rounds d up to whole seconds as the headers require.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
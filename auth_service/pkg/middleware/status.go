@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxCachedStatuses bounds the StatusCache, expired entries are dropped once
+// it is reached.
+const maxCachedStatuses = 10000
+
+// UserStatuses reports the account status of a user, "" when the user does
+// not exist.
+type UserStatuses interface {
+	Status(ctx context.Context, userID int64) (string, error)
+}
+
+// StatusSource loads the account status of a user, "" when the user does not
+// exist.
+type StatusSource interface {
+	GetStatus(userID int64) (string, error)
+}
+
+type statusEntry struct {
+	status    string
+	expiresAt time.Time
+}
+
+// StatusCache remembers user statuses for ttl so Authenticate does not query
+// the database on every request. A status change takes up to ttl to reach
+// requests made with tokens issued before it.
+type StatusCache struct {
+	source  StatusSource
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[int64]statusEntry
+}
+
+func NewStatusCache(source StatusSource, ttl time.Duration) *StatusCache {
+	return &StatusCache{source: source, ttl: ttl, entries: map[int64]statusEntry{}}
+}
+
+func (c *StatusCache) Status(ctx context.Context, userID int64) (string, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[userID]
+	c.mu.Unlock()
+
+	if ok && now.Before(entry.expiresAt) {
+		return entry.status, nil
+	}
+
+	status, err := c.source.GetStatus(userID)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxCachedStatuses {
+		for id, cached := range c.entries {
+			if !now.Before(cached.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+	}
+
+	if len(c.entries) < maxCachedStatuses {
+		c.entries[userID] = statusEntry{status, now.Add(c.ttl)}
+	}
+
+	return status, nil
+}
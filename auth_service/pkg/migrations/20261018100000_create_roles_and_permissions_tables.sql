@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS roles (
+    id serial PRIMARY KEY,
+    name varchar(64) NOT NULL UNIQUE,
+    description varchar(255),
+    is_system boolean DEFAULT false NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id serial PRIMARY KEY,
+    name varchar(64) NOT NULL UNIQUE,
+    description varchar(255)
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id integer NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id integer NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id integer NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (user_id, role_id)
+);
+
+INSERT INTO roles (name, description, is_system) VALUES
+    ('admin', 'Full access to user and role management', true),
+    ('user', 'Default role for registered users', true);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:read', 'List and view users'),
+    ('users:update', 'Edit any user'),
+    ('users:delete', 'Delete any user'),
+    ('roles:read', 'List roles and permissions'),
+    ('roles:manage', 'Create, edit, delete and assign roles');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin';
+
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id FROM users u CROSS JOIN roles r WHERE r.name = 'user';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
+-- +goose StatementEnd
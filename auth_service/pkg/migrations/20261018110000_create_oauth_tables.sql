@@ -0,0 +1,58 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id serial PRIMARY KEY,
+    client_id varchar(64) NOT NULL UNIQUE,
+    client_secret_hash varchar(64),
+    name varchar(255) NOT NULL,
+    redirect_uris text[] DEFAULT '{}' NOT NULL,
+    grant_types text[] DEFAULT '{}' NOT NULL,
+    scopes text[] DEFAULT '{}' NOT NULL,
+    is_confidential boolean DEFAULT true NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS oauth_authorization_codes (
+    id bigserial PRIMARY KEY,
+    code_hash varchar(64) NOT NULL UNIQUE,
+    client_id varchar(64) NOT NULL REFERENCES oauth_clients(client_id) ON DELETE CASCADE,
+    user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redirect_uri text NOT NULL,
+    scope text NOT NULL DEFAULT '',
+    code_challenge varchar(128) NOT NULL,
+    code_challenge_method varchar(10) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    used_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS oauth_consents (
+    user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id varchar(64) NOT NULL REFERENCES oauth_clients(client_id) ON DELETE CASCADE,
+    scope text NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP,
+    PRIMARY KEY (user_id, client_id)
+);
+
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS client_id varchar(64);
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS scope text;
+
+INSERT INTO permissions (name, description) VALUES
+    ('clients:manage', 'Register and remove OAuth clients');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin' AND p.name = 'clients:manage';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'clients:manage';
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS scope;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS client_id;
+DROP TABLE IF EXISTS oauth_consents;
+DROP TABLE IF EXISTS oauth_authorization_codes;
+DROP TABLE IF EXISTS oauth_clients;
+-- +goose StatementEnd
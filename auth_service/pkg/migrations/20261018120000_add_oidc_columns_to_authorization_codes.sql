@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE oauth_authorization_codes ADD COLUMN IF NOT EXISTS nonce varchar(255);
+ALTER TABLE oauth_authorization_codes ADD COLUMN IF NOT EXISTS auth_time TIMESTAMP DEFAULT NOW() NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE oauth_authorization_codes DROP COLUMN IF EXISTS auth_time;
+ALTER TABLE oauth_authorization_codes DROP COLUMN IF EXISTS nonce;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id serial PRIMARY KEY,
+    kid varchar(64) NOT NULL UNIQUE,
+    algorithm varchar(16) NOT NULL,
+    private_key_encrypted bytea NOT NULL,
+    public_key bytea NOT NULL,
+    state varchar(16) NOT NULL CHECK (state IN ('next', 'active', 'retired')),
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    activated_at TIMESTAMP,
+    retired_at TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS signing_keys_single_active_idx ON signing_keys (state) WHERE state = 'active';
+
+INSERT INTO permissions (name, description) VALUES
+    ('keys:manage', 'View and rotate token signing keys');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin' AND p.name = 'keys:manage';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'keys:manage';
+DROP TABLE IF EXISTS signing_keys;
+-- +goose StatementEnd
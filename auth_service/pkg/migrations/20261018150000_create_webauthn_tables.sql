@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+    id bigserial PRIMARY KEY,
+    user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credential_id bytea NOT NULL UNIQUE,
+    public_key bytea NOT NULL,
+    attestation_type varchar(32) NOT NULL,
+    transports text[] DEFAULT '{}' NOT NULL,
+    aaguid bytea,
+    sign_count bigint DEFAULT 0 NOT NULL,
+    clone_warning boolean DEFAULT false NOT NULL,
+    backup_eligible boolean DEFAULT false NOT NULL,
+    backup_state boolean DEFAULT false NOT NULL,
+    name varchar(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    last_used_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON webauthn_credentials(user_id);
+
+CREATE TABLE IF NOT EXISTS webauthn_sessions (
+    id varchar(64) PRIMARY KEY,
+    user_id integer REFERENCES users(id) ON DELETE CASCADE,
+    ceremony varchar(32) NOT NULL,
+    data jsonb NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webauthn_sessions;
+DROP TABLE IF EXISTS webauthn_credentials;
+-- +goose StatementEnd
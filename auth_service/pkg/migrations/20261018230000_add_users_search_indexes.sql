@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- the expressions must stay in sync with the search query in domain/auth
+CREATE INDEX IF NOT EXISTS users_email_trgm_idx ON users USING gin (email gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_audit_trgm_idx ON users USING gin (
+    (coalesce(created_by, '') || ' ' || coalesce(updated_by, '') || ' ' || coalesce(deleted_by, '')) gin_trgm_ops
+);
+CREATE INDEX IF NOT EXISTS users_search_idx ON users USING gin ((
+    setweight(to_tsvector('simple', regexp_replace(email, '[@._+-]+', ' ', 'g')), 'A') ||
+    setweight(to_tsvector('simple', regexp_replace(
+        coalesce(created_by, '') || ' ' || coalesce(updated_by, '') || ' ' || coalesce(deleted_by, ''),
+        '[@._+-]+', ' ', 'g')), 'B')
+));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_search_idx;
+DROP INDEX IF EXISTS users_audit_trgm_idx;
+DROP INDEX IF EXISTS users_email_trgm_idx;
+-- +goose StatementEnd
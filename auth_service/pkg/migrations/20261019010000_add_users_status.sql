@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status varchar(16) NOT NULL DEFAULT 'active';
+UPDATE users SET status = 'deleted' WHERE deleted_at IS NOT NULL;
+ALTER TABLE users ADD CONSTRAINT users_status_check
+    CHECK (status IN ('pending', 'active', 'suspended', 'locked', 'deleted'));
+
+CREATE TABLE IF NOT EXISTS user_status_changes (
+    id serial PRIMARY KEY,
+    user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_status varchar(16) NOT NULL,
+    to_status varchar(16) NOT NULL,
+    reason text NOT NULL DEFAULT '',
+    actor varchar(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL
+);
+CREATE INDEX IF NOT EXISTS user_status_changes_user_id_idx ON user_status_changes (user_id, created_at);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:status', 'Suspend, lock and reactivate users');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin' AND p.name = 'users:status';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'users:status';
+DROP TABLE IF EXISTS user_status_changes;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_status_check;
+ALTER TABLE users DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
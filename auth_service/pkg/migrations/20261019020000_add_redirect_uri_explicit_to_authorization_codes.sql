@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE oauth_authorization_codes ADD COLUMN IF NOT EXISTS redirect_uri_explicit boolean DEFAULT true NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE oauth_authorization_codes DROP COLUMN IF EXISTS redirect_uri_explicit;
+-- +goose StatementEnd
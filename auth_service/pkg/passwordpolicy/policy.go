@@ -0,0 +1,222 @@
+package passwordpolicy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ccojocar/zxcvbn-go"
+	"github.com/helyus1412/auth-service/pkg/breach"
+	"github.com/helyus1412/auth-service/pkg/hasher"
+)
+
+// Rules reported in a Violation.
+const (
+	RuleMinLength = "min_length"
+	RuleMaxLength = "max_length"
+	RuleLower     = "lower"
+	RuleUpper     = "upper"
+	RuleDigit     = "digit"
+	RuleSymbol    = "symbol"
+	RuleStrength  = "strength"
+	RuleEmail     = "email"
+	RuleHistory   = "history"
+	RuleBreached  = "breached"
+)
+
+// Character classes accepted in Config.RequiredClasses.
+const (
+	ClassLower  = "lower"
+	ClassUpper  = "upper"
+	ClassDigit  = "digit"
+	ClassSymbol = "symbol"
+)
+
+// minEmailLength keeps very short local parts such as "jo" from rejecting
+// half of all passwords.
+const minEmailLength = 3
+
+type Config struct {
+	MinLength int
+	MaxLength int
+	// RequiredClasses lists the character classes that must each appear at
+	// least once.
+	RequiredClasses []string
+	// MinScore is the lowest accepted zxcvbn score, from 0 (too guessable) to
+	// 4 (very unguessable).
+	MinScore int
+	// RejectEmail refuses passwords containing the local part of the account's
+	// email address.
+	RejectEmail bool
+	// History is the number of previous passwords that may not be reused.
+	History int
+}
+
+// Violation is a rule a password failed.
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+// Policy decides whether a new password is acceptable.
+type Policy struct {
+	config         Config
+	passwordHasher hasher.PasswordHasher
+	breachChecker  breach.Checker
+}
+
+// New builds a policy, passwordHasher verifies the password against previous
+// hashes. Passwords are only looked up in breaches when breachChecker is not
+// nil.
+func New(config Config, passwordHasher hasher.PasswordHasher, breachChecker breach.Checker) *Policy {
+	return &Policy{config, passwordHasher, breachChecker}
+}
+
+// History is the number of previous password hashes Check should receive.
+func (p *Policy) History() int {
+	return p.config.History
+}
+
+// Check returns every rule password breaks, nil when it is acceptable. email
+// is the address of the account and previous its most recent password hashes,
+// either may be empty. An error means the breach lookup failed, the returned
+// violations are complete otherwise.
+func (p *Policy) Check(ctx context.Context, password string, email string, previous []string) ([]Violation, error) {
+	var violations []Violation
+
+	length := utf8.RuneCountInString(password)
+	if length < p.config.MinLength {
+		violations = append(violations, Violation{RuleMinLength,
+			fmt.Sprintf("password must be at least %d characters long", p.config.MinLength)})
+	}
+
+	tooLong := p.config.MaxLength > 0 && length > p.config.MaxLength
+	if tooLong {
+		violations = append(violations, Violation{RuleMaxLength,
+			fmt.Sprintf("password must be at most %d characters long", p.config.MaxLength)})
+	}
+
+	violations = append(violations, p.checkClasses(password)...)
+
+	localPart := emailLocalPart(email)
+	if p.config.RejectEmail && containsEmail(password, localPart) {
+		violations = append(violations, Violation{RuleEmail, "password must not contain your email address"})
+	}
+
+	// estimating the strength of an overly long password is needlessly slow
+	if !tooLong && p.config.MinScore > 0 {
+		var inputs []string
+		if email != "" {
+			inputs = []string{strings.ToLower(email), localPart}
+		}
+
+		if zxcvbn.PasswordStrength(password, inputs).Score < p.config.MinScore {
+			violations = append(violations, Violation{RuleStrength, "password is too easy to guess"})
+		}
+	}
+
+	if p.reused(password, previous) {
+		violations = append(violations, Violation{RuleHistory,
+			fmt.Sprintf("password must differ from your last %d passwords", p.config.History)})
+	}
+
+	if p.breachChecker == nil || tooLong {
+		return violations, nil
+	}
+
+	count, err := p.breachChecker.Count(ctx, password)
+	if err != nil {
+		return violations, err
+	}
+
+	if count > 0 {
+		violations = append(violations, Violation{RuleBreached,
+			"password has appeared in a data breach, choose a different one"})
+	}
+
+	return violations, nil
+}
+
+func (p *Policy) checkClasses(password string) []Violation {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsLetter(r):
+			symbol = true
+		}
+	}
+
+	var violations []Violation
+	for _, class := range p.config.RequiredClasses {
+		switch {
+		case class == ClassLower && !lower:
+			violations = append(violations, Violation{RuleLower, "password must contain a lowercase letter"})
+		case class == ClassUpper && !upper:
+			violations = append(violations, Violation{RuleUpper, "password must contain an uppercase letter"})
+		case class == ClassDigit && !digit:
+			violations = append(violations, Violation{RuleDigit, "password must contain a digit"})
+		case class == ClassSymbol && !symbol:
+			violations = append(violations, Violation{RuleSymbol, "password must contain a symbol"})
+		}
+	}
+
+	return violations
+}
+
+// reused verifies password against at most History previous hashes. Hashes
+// that can no longer be verified, e.g. of an algorithm that was since removed,
+// are skipped.
+func (p *Policy) reused(password string, previous []string) bool {
+	if p.config.History <= 0 {
+		return false
+	}
+
+	if len(previous) > p.config.History {
+		previous = previous[:p.config.History]
+	}
+
+	for _, encoded := range previous {
+		ok, err := p.passwordHasher.Verify(encoded, password)
+		if err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func emailLocalPart(email string) string {
+	local, _, _ := strings.Cut(email, "@")
+	// sub-addressing, jane+shop@example.com belongs to jane
+	local, _, _ = strings.Cut(local, "+")
+
+	return strings.ToLower(local)
+}
+
+// containsEmail ignores case and punctuation, so jane.doe is found in
+// Janedoe-2024 as well.
+func containsEmail(password string, localPart string) bool {
+	localPart = alphanumeric(localPart)
+	if utf8.RuneCountInString(localPart) < minEmailLength {
+		return false
+	}
+
+	return strings.Contains(alphanumeric(password), localPart)
+}
+
+func alphanumeric(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
@@ -0,0 +1,153 @@
+package passwordpolicy
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/helyus1412/auth-service/pkg/breach"
+	"github.com/helyus1412/auth-service/pkg/hasher"
+)
+
+// plainHasher stores passwords as "plain$<password>" so histories are easy to
+// write down.
+type plainHasher struct{}
+
+func (plainHasher) Hash(password string) (string, error) {
+	return "plain$" + password, nil
+}
+
+func (h plainHasher) Verify(encoded string, password string) (bool, error) {
+	if !h.Supports(encoded) {
+		return false, hasher.ErrUnsupportedHash
+	}
+
+	return encoded == "plain$"+password, nil
+}
+
+func (plainHasher) Supports(encoded string) bool {
+	return strings.HasPrefix(encoded, "plain$")
+}
+
+func (plainHasher) NeedsRehash(encoded string) bool {
+	return false
+}
+
+// breachedPasswords counts the passwords in it as breached. A nil map fails
+// every lookup.
+type breachedPasswords map[string]int
+
+var errLookup = errors.New("lookup failed")
+
+func (b breachedPasswords) Count(ctx context.Context, password string) (int, error) {
+	if b == nil {
+		return 0, errLookup
+	}
+
+	return b[password], nil
+}
+
+func TestCheck(t *testing.T) {
+	lengthOnly := Config{MinLength: 12, MaxLength: 128}
+	allClasses := Config{RequiredClasses: []string{ClassLower, ClassUpper, ClassDigit, ClassSymbol}}
+	rejectEmail := Config{RejectEmail: true}
+	strength := Config{MinScore: 3}
+	history := Config{History: 2}
+
+	tests := []struct {
+		name     string
+		config   Config
+		password string
+		email    string
+		previous []string
+		breached breach.Checker
+		want     []string
+	}{
+		{name: "long enough", config: lengthOnly, password: "abcdefghijkl"},
+		{name: "too short", config: lengthOnly, password: "abcdefghijk", want: []string{RuleMinLength}},
+		// length counts characters, not bytes
+		{name: "multibyte characters", config: lengthOnly, password: strings.Repeat("ü", 12)},
+		{name: "multibyte too short", config: lengthOnly, password: strings.Repeat("ü", 11), want: []string{RuleMinLength}},
+		{name: "longest", config: lengthOnly, password: strings.Repeat("a", 128)},
+		{name: "too long", config: lengthOnly, password: strings.Repeat("a", 129), want: []string{RuleMaxLength}},
+		{name: "no maximum", config: Config{}, password: strings.Repeat("a", 1000)},
+
+		{name: "every class", config: allClasses, password: "aB3$"},
+		{name: "lower case only", config: allClasses, password: "abc", want: []string{RuleUpper, RuleDigit, RuleSymbol}},
+		{name: "no letters", config: allClasses, password: "123 456", want: []string{RuleLower, RuleUpper}},
+		{name: "space is a symbol", config: Config{RequiredClasses: []string{ClassSymbol}}, password: "a b"},
+		{name: "non-ascii letters", config: Config{RequiredClasses: []string{ClassLower, ClassUpper}}, password: "éÉ"},
+
+		{name: "contains email", config: rejectEmail, password: "x-jane.doe-2024", email: "jane.doe@example.com", want: []string{RuleEmail}},
+		{name: "contains email ignoring case and punctuation", config: rejectEmail, password: "JaneDoe2024", email: "jane.doe@example.com", want: []string{RuleEmail}},
+		{name: "contains sub-addressed email", config: rejectEmail, password: "jane-2024", email: "jane+shop@example.com", want: []string{RuleEmail}},
+		{name: "short local part", config: rejectEmail, password: "jo-jo-jo-jo", email: "jo@example.com"},
+		{name: "unrelated email", config: rejectEmail, password: "x-jane.doe-2024", email: "john@example.com"},
+		{name: "email allowed", config: Config{}, password: "x-jane.doe-2024", email: "jane.doe@example.com"},
+		{name: "no email", config: rejectEmail, password: "x-jane.doe-2024"},
+
+		{name: "strong", config: strength, password: "correct-Horse-battery-staple-91"},
+		{name: "guessable", config: strength, password: "password1234", want: []string{RuleStrength}},
+		{name: "guessable from email", config: strength, password: "jane.doe.example", email: "jane.doe@example.com", want: []string{RuleStrength}},
+		// too long to estimate, reported as too long only
+		{name: "strength of an overly long password", config: Config{MaxLength: 8, MinScore: 3}, password: "aaaaaaaaa", want: []string{RuleMaxLength}},
+
+		{name: "new password", config: history, password: "new", previous: []string{"plain$old", "plain$older"}},
+		{name: "previous password", config: history, password: "older", previous: []string{"plain$old", "plain$older"}, want: []string{RuleHistory}},
+		{name: "beyond the history", config: history, password: "oldest", previous: []string{"plain$old", "plain$older", "plain$oldest"}},
+		{name: "unverifiable hash", config: history, password: "old", previous: []string{"md5$old"}},
+		{name: "history off", config: Config{}, password: "old", previous: []string{"plain$old"}},
+
+		{name: "not breached", config: Config{}, password: "secret", breached: breachedPasswords{"password": 3}},
+		{name: "breached", config: Config{}, password: "password", breached: breachedPasswords{"password": 3}, want: []string{RuleBreached}},
+		// overly long passwords are never looked up
+		{name: "breach of an overly long password", config: Config{MaxLength: 4}, password: "password", breached: breachedPasswords{"password": 3}, want: []string{RuleMaxLength}},
+
+		{
+			name:     "every rule reported",
+			config:   Config{MinLength: 12, RequiredClasses: []string{ClassDigit}, RejectEmail: true, MinScore: 3, History: 1},
+			password: "janedoe",
+			email:    "jane.doe@example.com",
+			previous: []string{"plain$janedoe"},
+			breached: breachedPasswords{"janedoe": 1},
+			want:     []string{RuleMinLength, RuleDigit, RuleEmail, RuleStrength, RuleHistory, RuleBreached},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := New(tt.config, plainHasher{}, tt.breached).Check(context.Background(), tt.password, tt.email, tt.previous)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			var got []string
+			for _, violation := range violations {
+				if violation.Message == "" {
+					t.Errorf("violation %s has no message", violation.Rule)
+				}
+				got = append(got, violation.Rule)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Check() rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckBreachLookupFails(t *testing.T) {
+	policy := New(Config{MinLength: 12}, plainHasher{}, breachedPasswords(nil))
+
+	violations, err := policy.Check(context.Background(), "short", "", nil)
+	if !errors.Is(err, errLookup) {
+		t.Fatalf("Check() error = %v, want %v", err, errLookup)
+	}
+
+	// the rules checked before the lookup are still reported
+	if len(violations) != 1 || violations[0].Rule != RuleMinLength {
+		t.Fatalf("Check() = %v, want the min_length violation", violations)
+	}
+}
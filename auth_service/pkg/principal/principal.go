@@ -0,0 +1,87 @@
+package principal
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+type contextKey struct{}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID    int64
+	Email     string
+	Roles     []string
+	Scope     string
+	ClientID  string
+	AMR       []string
+	SessionID string
+	// TokenID is the jti of the access token the request was made with.
+	TokenID   string
+	ExpiresAt time.Time
+}
+
+// NewContext returns a copy of ctx carrying the principal.
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// HasRole reports whether the principal carries the given role.
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasAMR reports whether the principal authenticated with the given method.
+func (p *Principal) HasAMR(method string) bool {
+	for _, m := range p.AMR {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Actor names the principal in audit columns such as deleted_by: the user ID
+// or, for machine tokens, the client ID. Emails are left out so anonymizing a
+// deleted user doesn't have to rewrite the rows other users' changes touched.
+func (p *Principal) Actor() string {
+	if p.UserID == 0 && p.ClientID != "" {
+		return "client:" + p.ClientID
+	}
+
+	return "user:" + strconv.FormatInt(p.UserID, 10)
+}
+
+// Actors recorded in audit columns when no principal made the change.
+const (
+	// ActorSelf marks changes users made to their own account without being
+	// signed in, e.g. registering or resetting their password.
+	ActorSelf = "self"
+	// ActorSystem marks changes made by the service itself, e.g. background
+	// jobs or command line tools.
+	ActorSystem = "system"
+)
+
+// ActorFrom returns the Actor of the principal stored in ctx, or fallback
+// when the request is not authenticated.
+func ActorFrom(ctx context.Context, fallback string) string {
+	if p, ok := FromContext(ctx); ok {
+		return p.Actor()
+	}
+
+	return fallback
+}
+
+// FromContext returns the principal stored in ctx, if any.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(*Principal)
+	return p, ok && p != nil
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is a token bucket: Burst requests may be made at once and the bucket
+// refills at Requests per Per.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// PerMinute allows n requests a minute with bursts of up to n.
+func PerMinute(n int) Limit {
+	return Limit{Requests: n, Per: time.Minute, Burst: n}
+}
+
+// PerHour allows n requests an hour with bursts of up to n.
+func PerHour(n int) Limit {
+	return Limit{Requests: n, Per: time.Hour, Burst: n}
+}
+
+// interval is the time it takes to refill one token.
+func (l Limit) interval() time.Duration {
+	return l.Per / time.Duration(l.Requests)
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until the next request is allowed, zero when allowed.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// Store keeps the buckets. Take consumes one token from the bucket at key.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// take applies the token bucket to a bucket last seen with tokens at updated
+// and returns the outcome with the new token count.
+func take(limit Limit, tokens float64, updated time.Time, now time.Time) (Result, float64) {
+	burst := float64(limit.Burst)
+	interval := limit.interval()
+
+	elapsed := now.Sub(updated)
+	if elapsed > 0 {
+		tokens = math.Min(burst, tokens+float64(elapsed)/float64(interval))
+	}
+
+	result := Result{Limit: limit.Burst}
+
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - tokens) * float64(interval))
+	}
+
+	result.Remaining = int(math.Floor(tokens))
+	result.Reset = time.Duration((burst - tokens) * float64(interval))
+
+	return result, tokens
+}
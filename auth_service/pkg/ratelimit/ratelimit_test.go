@@ -0,0 +1,162 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// clock is a settable time source for the stores.
+type clock struct {
+	now time.Time
+}
+
+func newClock() *clock {
+	return &clock{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+// step advances the clock, takes a token and states the expected outcome.
+type step struct {
+	advance    time.Duration
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+	reset      time.Duration
+}
+
+// three requests a minute refill one token every 20s
+var threePerMinute = Limit{Requests: 3, Per: time.Minute, Burst: 3}
+
+var bucketTests = []struct {
+	name  string
+	limit Limit
+	steps []step
+}{
+	{
+		name:  "burst",
+		limit: threePerMinute,
+		steps: []step{
+			{allowed: true, remaining: 2, reset: 20 * time.Second},
+			{allowed: true, remaining: 1, reset: 40 * time.Second},
+			{allowed: true, remaining: 0, reset: time.Minute},
+			{allowed: false, remaining: 0, retryAfter: 20 * time.Second, reset: time.Minute},
+		},
+	},
+	{
+		name:  "refill",
+		limit: threePerMinute,
+		steps: []step{
+			{allowed: true, remaining: 2, reset: 20 * time.Second},
+			{allowed: true, remaining: 1, reset: 40 * time.Second},
+			{allowed: true, remaining: 0, reset: time.Minute},
+			{advance: 10 * time.Second, allowed: false, remaining: 0, retryAfter: 10 * time.Second, reset: 50 * time.Second},
+			{advance: 10 * time.Second, allowed: true, remaining: 0, reset: time.Minute},
+			{advance: 40 * time.Second, allowed: true, remaining: 1, reset: 40 * time.Second},
+		},
+	},
+	{
+		name:  "refill stops at burst",
+		limit: threePerMinute,
+		steps: []step{
+			{allowed: true, remaining: 2, reset: 20 * time.Second},
+			{advance: time.Hour, allowed: true, remaining: 2, reset: 20 * time.Second},
+		},
+	},
+	{
+		name:  "burst above rate",
+		limit: Limit{Requests: 1, Per: time.Second, Burst: 2},
+		steps: []step{
+			{allowed: true, remaining: 1, reset: time.Second},
+			{allowed: true, remaining: 0, reset: 2 * time.Second},
+			{allowed: false, remaining: 0, retryAfter: time.Second, reset: 2 * time.Second},
+			{advance: time.Second, allowed: true, remaining: 0, reset: 2 * time.Second},
+		},
+	},
+}
+
+// runBucketTests plays every bucketTests case against a fresh store.
+func runBucketTests(t *testing.T, newStore func(*clock) Store) {
+	for _, tt := range bucketTests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := newClock()
+			store := newStore(now)
+
+			for i, s := range tt.steps {
+				now.now = now.now.Add(s.advance)
+
+				result, err := store.Take(context.Background(), "key", tt.limit)
+				if err != nil {
+					t.Fatalf("step %d: Take() error = %v", i, err)
+				}
+
+				want := Result{
+					Allowed:    s.allowed,
+					Limit:      tt.limit.Burst,
+					Remaining:  s.remaining,
+					RetryAfter: s.retryAfter,
+					Reset:      s.reset,
+				}
+				if result != want {
+					t.Fatalf("step %d: Take() = %+v, want %+v", i, result, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	runBucketTests(t, func(c *clock) Store {
+		store := NewMemoryStore().(*memoryStore)
+		store.now = c.Now
+		return store
+	})
+}
+
+func TestMemoryStoreKeysAreIndependent(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Requests: 1, Per: time.Minute, Burst: 1}
+
+	for _, key := range []string{"a", "b"} {
+		result, err := store.Take(context.Background(), key, limit)
+		if err != nil || !result.Allowed {
+			t.Fatalf("Take(%q) = %+v, %v, want allowed", key, result, err)
+		}
+	}
+
+	result, err := store.Take(context.Background(), "a", limit)
+	if err != nil || result.Allowed {
+		t.Fatalf("second Take(a) = %+v, %v, want denied", result, err)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	now := newClock()
+	store := NewMemoryStore().(*memoryStore)
+	store.now = now.Now
+	store.lastSweep = now.now
+
+	limit := Limit{Requests: 1, Per: time.Minute, Burst: 1}
+
+	if _, err := store.Take(context.Background(), "idle", limit); err != nil {
+		t.Fatal(err)
+	}
+
+	// the idle bucket is full again long before the next sweep is due
+	now.now = now.now.Add(sweepInterval + time.Second)
+
+	if _, err := store.Take(context.Background(), "active", limit); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := store.buckets["idle"]; ok {
+		t.Error("sweep kept a bucket that refilled completely")
+	}
+
+	if _, ok := store.buckets["active"]; !ok {
+		t.Error("sweep dropped the bucket just taken from")
+	}
+}
@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript refills and consumes the bucket atomically. The bucket is a hash
+// of the token count and the last update in milliseconds, expiring once it
+// would be full again.
+var takeScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(state[1])
+local updated = tonumber(state[2])
+
+if tokens == nil then
+	tokens = burst
+	updated = now
+end
+
+local elapsed = now - updated
+if elapsed > 0 then
+	tokens = math.min(burst, tokens + elapsed / interval)
+end
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) * interval)
+end
+
+local reset = math.ceil((burst - tokens) * interval)
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.max(reset, 1))
+
+return {allowed, math.floor(tokens), retry, reset}
+`)
+
+type redisStore struct {
+	client *redis.Client
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedisStore keeps buckets in Redis so limits hold across replicas. Keys
+// are namespaced with prefix.
+func NewRedisStore(client *redis.Client, prefix string) Store {
+	return &redisStore{client, prefix, time.Now}
+}
+
+func (s *redisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	interval := limit.interval().Milliseconds()
+	if interval < 1 {
+		interval = 1
+	}
+
+	res, err := takeScript.Run(ctx, s.client, []string{s.prefix + key},
+		limit.Burst, interval, strconv.FormatInt(s.now().UnixMilli(), 10)).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:    res[0] == 1,
+		Limit:      limit.Burst,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+		Reset:      time.Duration(res[3]) * time.Millisecond,
+	}, nil
+}
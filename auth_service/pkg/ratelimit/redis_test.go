@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return server, client
+}
+
+func TestRedisStore(t *testing.T) {
+	runBucketTests(t, func(c *clock) Store {
+		_, client := newTestRedis(t)
+		store := NewRedisStore(client, "ratelimit:").(*redisStore)
+		store.now = c.Now
+		return store
+	})
+}
+
+func TestRedisStoreTTL(t *testing.T) {
+	server, client := newTestRedis(t)
+	now := newClock()
+	store := NewRedisStore(client, "ratelimit:").(*redisStore)
+	store.now = now.Now
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		takes   int
+		wantTTL time.Duration
+	}{
+		// the bucket expires once it would be full again
+		{name: "one token used", takes: 1, wantTTL: 20 * time.Second},
+		{name: "bucket empty", takes: 3, wantTTL: time.Minute},
+		{name: "denied request", takes: 4, wantTTL: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.FlushAll()
+
+			for range tt.takes {
+				if _, err := store.Take(ctx, "key", threePerMinute); err != nil {
+					t.Fatalf("Take() error = %v", err)
+				}
+			}
+
+			if ttl := server.TTL("ratelimit:key"); ttl != tt.wantTTL {
+				t.Fatalf("TTL = %s, want %s", ttl, tt.wantTTL)
+			}
+
+			server.FastForward(tt.wantTTL)
+
+			if server.Exists("ratelimit:key") {
+				t.Fatal("bucket did not expire")
+			}
+
+			// an expired bucket starts full
+			result, err := store.Take(ctx, "key", threePerMinute)
+			if err != nil {
+				t.Fatalf("Take() error = %v", err)
+			}
+
+			if !result.Allowed || result.Remaining != threePerMinute.Burst-1 {
+				t.Fatalf("Take() after expiry = %+v, want a full bucket", result)
+			}
+		})
+	}
+}
+
+func TestRedisStorePrefix(t *testing.T) {
+	server, client := newTestRedis(t)
+
+	if _, err := NewRedisStore(client, "a:").Take(context.Background(), "key", threePerMinute); err != nil {
+		t.Fatal(err)
+	}
+
+	if !server.Exists("a:key") {
+		t.Fatal("bucket is not stored under the prefix")
+	}
+
+	result, err := NewRedisStore(client, "b:").Take(context.Background(), "key", threePerMinute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Remaining != threePerMinute.Burst-1 {
+		t.Fatalf("store with another prefix shares the bucket: %+v", result)
+	}
+}
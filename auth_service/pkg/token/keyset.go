@@ -0,0 +1,120 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is a private key together with its key ID and JWS algorithm.
+type SigningKey struct {
+	KeyID      string
+	Algorithm  string
+	PrivateKey crypto.Signer
+}
+
+// VerificationKey is a public key that is still accepted for verification.
+type VerificationKey struct {
+	KeyID     string
+	Algorithm string
+	PublicKey crypto.PublicKey
+}
+
+// KeySet supplies the keys used to sign and verify tokens.
+type KeySet interface {
+	// SigningKey returns the key new tokens are signed with.
+	SigningKey() (*SigningKey, error)
+	// VerificationKey looks up a published key by its ID.
+	VerificationKey(kid string) (*VerificationKey, error)
+	// VerificationKeys returns every published key.
+	VerificationKeys() ([]VerificationKey, error)
+}
+
+// GenerateKey creates a new private key for the given JWS algorithm.
+func GenerateKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case "RS256":
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case "EdDSA":
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	default:
+		return nil, fmt.Errorf("token: unsupported signing algorithm %q", algorithm)
+	}
+}
+
+// CheckKey verifies that key can be used with algorithm.
+func CheckKey(algorithm string, key crypto.Signer) error {
+	switch algorithm {
+	case "RS256":
+		if _, ok := key.(*rsa.PrivateKey); !ok {
+			return errors.New("token: RS256 requires an RSA private key")
+		}
+	case "EdDSA":
+		if _, ok := key.(ed25519.PrivateKey); !ok {
+			return errors.New("token: EdDSA requires an Ed25519 private key")
+		}
+	default:
+		return fmt.Errorf("token: unsupported signing algorithm %q", algorithm)
+	}
+
+	return nil
+}
+
+func signingMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case "RS256":
+		return jwt.SigningMethodRS256, nil
+	case "EdDSA":
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("token: unsupported signing algorithm %q", algorithm)
+	}
+}
+
+// LoadPrivateKey reads a PEM encoded PKCS#8 or PKCS#1 private key from disk.
+func LoadPrivateKey(path string) (crypto.Signer, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("token: read private key: %w", err)
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("token: private key is not PEM encoded")
+	}
+
+	if key, err := ParsePrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("token: parse private key: %w", err)
+	}
+
+	return key, nil
+}
+
+// ParsePrivateKey decodes a PKCS#8 DER private key.
+func ParsePrivateKey(der []byte) (crypto.Signer, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("token: unsupported private key type")
+	}
+
+	return signer, nil
+}
@@ -0,0 +1,248 @@
+package token
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Claims is the payload carried by access tokens issued by this service.
+type Claims struct {
+	Email    string   `json:"email,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+	Scope    string   `json:"scope,omitempty"`
+	ClientID string   `json:"client_id,omitempty"`
+	AMR      []string `json:"amr,omitempty"`
+	// SessionID ties first-party tokens to the sign-in they came from.
+	SessionID string `json:"sid,omitempty"`
+	// Purpose marks single-use tokens, such as an MFA challenge, that must
+	// never be accepted as access tokens.
+	Purpose string `json:"purpose,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// IDTokenClaims is the payload of an OpenID Connect ID token.
+type IDTokenClaims struct {
+	Email         string           `json:"email,omitempty"`
+	EmailVerified *bool            `json:"email_verified,omitempty"`
+	AuthTime      *jwt.NumericDate `json:"auth_time,omitempty"`
+	Nonce         string           `json:"nonce,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Config holds the settings used to sign and verify tokens.
+type Config struct {
+	Algorithm string
+	Issuer    string
+	Audience  []string
+	AccessTTL time.Duration
+	KeySet    KeySet
+}
+
+// Manager issues and verifies signed JWT access tokens.
+type Manager struct {
+	algorithm string
+	issuer    string
+	audience  []string
+	accessTTL time.Duration
+	keySet    KeySet
+}
+
+func NewManager(config Config) (*Manager, error) {
+	if config.KeySet == nil {
+		return nil, errors.New("token: key set is required")
+	}
+
+	if _, err := signingMethod(config.Algorithm); err != nil {
+		return nil, err
+	}
+
+	return &Manager{
+		algorithm: config.Algorithm,
+		issuer:    config.Issuer,
+		audience:  config.Audience,
+		accessTTL: config.AccessTTL,
+		keySet:    config.KeySet,
+	}, nil
+}
+
+// IssueAccessToken signs an access token and returns it together with its
+// expiry time. The caller sets the subject and custom claims; issuer, audience,
+// validity window and a unique token ID are filled in by the manager.
+func (m *Manager) IssueAccessToken(claims Claims) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.accessTTL)
+
+	claims.ID = uuid.NewString()
+	claims.Issuer = m.issuer
+	claims.Audience = m.audience
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.NotBefore = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+
+	signed, err := m.sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signed, expiresAt, nil
+}
+
+// IssuePurposeToken signs a short-lived token for a single purpose, such as
+// completing an MFA challenge.
+func (m *Manager) IssuePurposeToken(subject string, purpose string, ttl time.Duration) (string, error) {
+	var claims Claims
+	claims.Subject = subject
+
+	return m.IssuePurposeClaims(claims, purpose, ttl)
+}
+
+// IssuePurposeClaims is IssuePurposeToken carrying extra claims, such as the
+// email address a verification link was sent to.
+func (m *Manager) IssuePurposeClaims(claims Claims, purpose string, ttl time.Duration) (string, error) {
+	now := time.Now()
+
+	claims.Purpose = purpose
+	claims.Issuer = m.issuer
+	claims.Audience = jwt.ClaimStrings{purpose}
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+
+	return m.sign(claims)
+}
+
+// ParsePurposeToken verifies a token issued by IssuePurposeToken for purpose.
+func (m *Manager) ParsePurposeToken(raw string, purpose string) (*Claims, error) {
+	claims, err := m.parse(raw, jwt.WithAudience(purpose))
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != purpose {
+		return nil, errors.New("token: wrong token purpose")
+	}
+
+	return claims, nil
+}
+
+// IssueIDToken signs an OpenID Connect ID token for the given client.
+func (m *Manager) IssueIDToken(claims IDTokenClaims, clientID string) (string, error) {
+	now := time.Now()
+
+	claims.Issuer = m.issuer
+	claims.Audience = jwt.ClaimStrings{clientID}
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(m.accessTTL))
+
+	return m.sign(claims)
+}
+
+func (m *Manager) sign(claims jwt.Claims) (string, error) {
+	key, err := m.keySet.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	method, err := signingMethod(key.Algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	t := jwt.NewWithClaims(method, claims)
+	t.Header["kid"] = key.KeyID
+
+	return t.SignedString(key.PrivateKey)
+}
+
+// Algorithm returns the JWS algorithm used to sign tokens.
+func (m *Manager) Algorithm() string {
+	return m.algorithm
+}
+
+// Issuer returns the configured token issuer.
+func (m *Manager) Issuer() string {
+	return m.issuer
+}
+
+// AccessTTL returns the lifetime of issued access and ID tokens.
+func (m *Manager) AccessTTL() time.Duration {
+	return m.accessTTL
+}
+
+// JWKSet returns the public keys that verify tokens issued by this manager.
+func (m *Manager) JWKSet() (*JWKSet, error) {
+	keys, err := m.keySet.VerificationKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	set := &JWKSet{Keys: make([]JWK, 0, len(keys))}
+	for _, key := range keys {
+		jwk, err := NewJWK(key.PublicKey, key.KeyID, key.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set, nil
+}
+
+// Parse verifies the signature and standard claims of an access token and returns its claims.
+func (m *Manager) Parse(raw string) (*Claims, error) {
+	var options []jwt.ParserOption
+	if len(m.audience) > 0 {
+		options = append(options, jwt.WithAudience(m.audience[0]))
+	}
+
+	claims, err := m.parse(raw, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != "" {
+		return nil, errors.New("token: not an access token")
+	}
+
+	return claims, nil
+}
+
+func (m *Manager) parse(raw string, extra ...jwt.ParserOption) (*Claims, error) {
+	options := append([]jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "EdDSA"}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithExpirationRequired(),
+	}, extra...)
+
+	var claims Claims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, ok := t.Header["kid"].(string)
+		if !ok {
+			return nil, errors.New("token: missing key id")
+		}
+
+		key, err := m.keySet.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+
+		if key.Algorithm != t.Method.Alg() {
+			return nil, errors.New("token: algorithm does not match key")
+		}
+
+		return key.PublicKey, nil
+	}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &claims, nil
+}
+
+// UserSubject formats a user ID as a token subject.
+func UserSubject(userID int64) string {
+	return strconv.FormatInt(userID, 10)
+}
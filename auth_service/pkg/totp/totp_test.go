@@ -0,0 +1,136 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed of RFC 6238 Appendix B, "12345678901234567890".
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// RFC 6238 Appendix B, the last 6 of the 8 digits listed there
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code() error = %v", err)
+		}
+
+		if got != tt.want {
+			t.Errorf("Code() at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeLowerCaseSecret(t *testing.T) {
+	got, err := Code(strings.ToLower(rfcSecret), 1)
+	if err != nil || got != "287082" {
+		t.Fatalf("Code() = %s, %v, want 287082", got, err)
+	}
+}
+
+func TestCodeInvalidSecret(t *testing.T) {
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Fatal("Code() error = nil")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	code := func(step int64) string {
+		c, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", code(current), 0, current, true},
+		{"previous step within skew", code(current - 1), 0, current - 1, true},
+		{"next step within skew", code(current + 1), 0, current + 1, true},
+		{"outside skew before", code(current - 2), 0, 0, false},
+		{"outside skew after", code(current + 2), 0, 0, false},
+		{"surrounding spaces", " " + code(current) + "\n", 0, current, true},
+		{"wrong code", "000000", 0, 0, false},
+		{"too short", code(current)[:5], 0, 0, false},
+		{"too long", code(current) + "0", 0, 0, false},
+		// codes of steps at or before the last used one were consumed
+		{"replay of the current step", code(current), current, 0, false},
+		{"previous step after the current was used", code(current - 1), current, 0, false},
+		{"next step after the current was used", code(current + 1), current, current + 1, true},
+		{"current step after the previous was used", code(current), current - 1, current, true},
+		{"replay after the next step was used", code(current), current + 1, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now, tt.lastStep)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Fatalf("Validate() = %d, %v, want %d, %v", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidateReplay(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, err := Code(rfcSecret, Step(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	step, ok := Validate(rfcSecret, code, now, 0)
+	if !ok {
+		t.Fatal("first use rejected")
+	}
+
+	// the same code stays valid for up to three periods without the last step
+	for _, later := range []time.Duration{0, Period, 2 * Period} {
+		if _, ok := Validate(rfcSecret, code, now.Add(later), step); ok {
+			t.Fatalf("replay %s later accepted", later)
+		}
+	}
+}
+
+func TestURI(t *testing.T) {
+	got := URI("Auth Service", "user@example.com", rfcSecret)
+	want := "otpauth://totp/Auth%20Service:user@example.com?algorithm=SHA1&digits=6&issuer=Auth+Service&period=30&secret=" + rfcSecret
+
+	if got != want {
+		t.Fatalf("URI() = %s, want %s", got, want)
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := encoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("GenerateSecret() = %q, want 20 base32 encoded bytes", secret)
+	}
+}
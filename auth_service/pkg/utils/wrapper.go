@@ -18,10 +18,11 @@ type Result struct {
 }
 
 type MetaData struct {
-	Page      int     `json:"page"`
-	Quantity  int64   `json:"quantity"`
-	TotalPage float64 `json:"totalPage"`
-	TotalData int64   `json:"totalData"`
+	Page       int     `json:"page"`
+	Quantity   int64   `json:"quantity"`
+	TotalPage  float64 `json:"totalPage"`
+	TotalData  int64   `json:"totalData"`
+	NextCursor string  `json:"nextCursor,omitempty"`
 }
 
 type ResultCount struct {
@@ -32,12 +33,13 @@ type ResultCount struct {
 
 // BaseWrapperModel data structure
 type BaseWrapperModel struct {
-	Code          string      `json:"code"`
-	Message       string      `json:"message"`
-	SystemMessage string      `json:"system_message"`
-	Data          interface{} `json:"data"`
-	Meta          interface{} `json:"meta,omitempty"`
-	Timestamp     int64       `json:"timestamp"`
+	Code             string      `json:"code"`
+	Message          string      `json:"message"`
+	SystemMessage    string      `json:"system_message"`
+	Data             interface{} `json:"data"`
+	Meta             interface{} `json:"meta,omitempty"`
+	ValidationErrors interface{} `json:"validation_errors,omitempty"`
+	Timestamp        int64       `json:"timestamp"`
 }
 
 // Response function
@@ -69,11 +71,12 @@ func PaginationResponse(data interface{}, meta interface{}, message string, code
 func ResponseError(err interface{}, c echo.Context) error {
 	errObj := getErrorStatusCode(err)
 	result := BaseWrapperModel{
-		Data:          errObj.Data,
-		Message:       errObj.Message,
-		SystemMessage: errObj.SystemMessage,
-		Code:          getRespCode(errObj.Code, errObj.CustomCode),
-		Timestamp:     time.Now().Unix(),
+		Data:             errObj.Data,
+		Message:          errObj.Message,
+		SystemMessage:    errObj.SystemMessage,
+		ValidationErrors: errObj.ValidationErrors,
+		Code:             getRespCode(errObj.Code, errObj.CustomCode),
+		Timestamp:        time.Now().Unix(),
 	}
 	return c.JSON(errObj.ResponseCode, result)
 }
@@ -126,6 +129,14 @@ func getErrorStatusCode(err interface{}) httpError.CommonError {
 		errData.Data = obj.Data
 		errData.Message = obj.Message
 		return errData
+	case httpError.ValidationError:
+		errData.ResponseCode = obj.Code
+		errData.CustomCode = obj.CustomCode
+		errData.Code = obj.Code
+		errData.Data = obj.Data
+		errData.Message = obj.Message
+		errData.ValidationErrors = obj.Errors
+		return errData
 	default:
 		errData.Code = http.StatusConflict
 		return errData
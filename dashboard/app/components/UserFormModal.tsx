@@ -1,6 +1,7 @@
 'use client';
 import { Modal, Form, Input, message } from 'antd';
 import axios from 'axios';
+import { authHeaders } from '../users/services/userApi';
 
 export default function UserFormModal({ open, onCancel, onSuccess, editingUser }: any) {
   const [form] = Form.useForm();
@@ -9,7 +10,7 @@ export default function UserFormModal({ open, onCancel, onSuccess, editingUser }
     const values = await form.validateFields();
     try {
       if (editingUser) {
-        await axios.put(`http://localhost:8000/users/${editingUser.id}`, values);
+        await axios.put(`http://localhost:8000/users/${editingUser.id}`, values, authHeaders());
         message.success('User updated');
       } else {
         await axios.post('http://localhost:8000/register', values);
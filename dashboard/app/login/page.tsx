@@ -1,23 +1,37 @@
 'use client';
-import { useState } from 'react';
+import { useEffect, useState } from 'react';
 import { Button, Form, Input, Card, message, Typography } from 'antd';
 import { useRouter } from 'next/navigation';
 import Link from 'next/link';
 import axios from 'axios';
+import { getAssertion, passkeySupported } from '@/lib/webauthn';
 
 const { Title } = Typography;
 
 export default function LoginPage() {
   const router = useRouter();
   const [loading, setLoading] = useState(false);
+  const [mfa, setMfa] = useState<{ token: string; methods: string[] } | null>(null);
+  const [canUsePasskey, setCanUsePasskey] = useState(false);
+
+  useEffect(() => setCanUsePasskey(passkeySupported()), []);
+
+  const signedIn = (data: any) => {
+    localStorage.setItem('token', data.access_token);
+    localStorage.setItem('refresh_token', data.refresh_token);
+    message.success('Login successful');
+    router.push('/users');
+  };
 
   const onFinish = async (values: any) => {
     setLoading(true);
     try {
       const res = await axios.post('http://localhost:8000/login', values);
-      localStorage.setItem('token', res.data.token);
-      message.success('Login successful');
-      router.push('/users');
+      if (res.data.data.mfa_required) {
+        setMfa({ token: res.data.data.mfa_token, methods: res.data.data.methods });
+        return;
+      }
+      signedIn(res.data.data);
     } catch (err: any) {
       console.error(err);
       message.error(err?.response?.data?.error || 'Invalid email or password');
@@ -26,6 +40,83 @@ export default function LoginPage() {
     }
   };
 
+  const onVerifyCode = async (values: any) => {
+    setLoading(true);
+    try {
+      const res = await axios.post('http://localhost:8000/login/mfa', { mfa_token: mfa?.token, ...values });
+      signedIn(res.data.data);
+    } catch (err: any) {
+      console.error(err);
+      message.error(err?.response?.data?.error || 'Invalid code');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const onVerifyPasskey = async () => {
+    setLoading(true);
+    try {
+      const begin = await axios.post('http://localhost:8000/login/mfa/passkey', { mfa_token: mfa?.token });
+      const credential = await getAssertion(begin.data.data.options);
+      const res = await axios.post('http://localhost:8000/login/mfa', {
+        mfa_token: mfa?.token,
+        session_id: begin.data.data.session_id,
+        credential,
+      });
+      signedIn(res.data.data);
+    } catch (err: any) {
+      console.error(err);
+      message.error(err?.response?.data?.error || 'Passkey verification failed');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const onPasskeyLogin = async () => {
+    setLoading(true);
+    try {
+      const begin = await axios.post('http://localhost:8000/login/passkey/begin');
+      const credential = await getAssertion(begin.data.data.options);
+      const res = await axios.post('http://localhost:8000/login/passkey/finish', {
+        session_id: begin.data.data.session_id,
+        credential,
+      });
+      signedIn(res.data.data);
+    } catch (err: any) {
+      console.error(err);
+      message.error(err?.response?.data?.error || 'Passkey sign-in failed');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  if (mfa) {
+    return (
+      <div className="auth-container">
+        <Card title={<Title level={4}>Two-factor authentication</Title>} className="auth-card">
+          {mfa.methods.includes('totp') && (
+            <Form layout="vertical" onFinish={onVerifyCode}>
+              <Form.Item name="code" label="Authenticator code">
+                <Input placeholder="123456" autoComplete="one-time-code" />
+              </Form.Item>
+              <Form.Item name="recovery_code" label="Or a recovery code">
+                <Input placeholder="xxxxx-xxxxx" />
+              </Form.Item>
+              <Button type="primary" htmlType="submit" block loading={loading}>
+                Verify
+              </Button>
+            </Form>
+          )}
+          {mfa.methods.includes('webauthn') && (
+            <Button style={{ marginTop: 16 }} block loading={loading} onClick={onVerifyPasskey}>
+              Use a passkey
+            </Button>
+          )}
+        </Card>
+      </div>
+    );
+  }
+
   return (
     <div className="auth-container">
       <Card title={<Title level={4}>Login</Title>} className="auth-card">
@@ -39,6 +130,11 @@ export default function LoginPage() {
           <Button type="primary" htmlType="submit" block loading={loading}>
             Login
           </Button>
+          {canUsePasskey && (
+            <Button style={{ marginTop: 8 }} block loading={loading} onClick={onPasskeyLogin}>
+              Sign in with a passkey
+            </Button>
+          )}
           <div style={{ marginTop: 16, textAlign: 'center' }}>
             Don't have an account? <Link href="/register">Register</Link>
           </div>
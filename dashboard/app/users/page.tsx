@@ -3,19 +3,34 @@ import { useEffect, useState } from 'react';
 import {
   App,
   Button,
+  Input,
   Table,
   Space,
   Typography,
 } from 'antd';
+import type { TablePaginationConfig } from 'antd';
+import type { SorterResult } from 'antd/es/table/interface';
 import { useRouter } from 'next/navigation';
 import axios from 'axios';
 import UserFormModal from '../components/UserFormModal';
+import { authHeaders } from './services/userApi';
 
 const { Title } = Typography;
 
 interface User {
   id: number;
   email: string;
+  created_at: string;
+  created_by: string | null;
+  updated_by: string | null;
+  deleted_by: string | null;
+}
+
+interface ListQuery {
+  page: number;
+  limit: number;
+  email: string;
+  sort: string;
 }
 
 export default function UserPage() {
@@ -25,14 +40,28 @@ export default function UserPage() {
   const [loading, setLoading] = useState(false);
   const [isModalOpen, setModalOpen] = useState(false);
   const [editingUser, setEditingUser] = useState<User | null>(null);
+  const [query, setQuery] = useState<ListQuery>({ page: 1, limit: 20, email: '', sort: 'id' });
+  const [total, setTotal] = useState(0);
 
-  const fetchUsers = async () => {
+  const fetchUsers = async (params: ListQuery = query) => {
     setLoading(true);
     try {
-      const res = await axios.get('http://localhost:8000/users');
-      const data = Array.isArray(res.data) ? res.data : res.data.data || [];
-      setUsers(data);
-    } catch (err) {
+      const res = await axios.get('http://localhost:8000/users', {
+        ...authHeaders(),
+        params: {
+          page: params.page,
+          limit: params.limit,
+          sort: params.sort,
+          ...(params.email ? { email: params.email } : {}),
+        },
+      });
+      setUsers(res.data.data || []);
+      setTotal(res.data.meta?.totalData ?? 0);
+    } catch (err: any) {
+      if (err?.response?.status === 401) {
+        router.push('/login');
+        return;
+      }
       message.error('Failed to fetch users');
     } finally {
       setLoading(false);
@@ -48,7 +77,7 @@ export default function UserPage() {
       cancelText: 'Cancel',
       onOk: async () => {
         try {
-          await axios.delete(`http://localhost:8000/users/${id}`);
+          await axios.delete(`http://localhost:8000/users/${id}`, authHeaders());
           message.success('User deleted successfully');
           fetchUsers();
         } catch (err) {
@@ -58,13 +87,52 @@ export default function UserPage() {
     });
   };
 
+  const logout = async () => {
+    try {
+      await axios.post(
+        'http://localhost:8000/logout',
+        { refresh_token: localStorage.getItem('refresh_token') ?? '' },
+        authHeaders(),
+      );
+    } catch (err) {
+      // the local tokens are dropped even if the server could not be reached
+    } finally {
+      localStorage.clear();
+      router.push('/login');
+    }
+  };
+
   useEffect(() => {
-    fetchUsers();
-  }, []);
+    fetchUsers(query);
+  }, [query]);
+
+  const onTableChange = (
+    pagination: TablePaginationConfig,
+    _filters: unknown,
+    sorter: SorterResult<User> | SorterResult<User>[],
+  ) => {
+    const { field, order } = Array.isArray(sorter) ? sorter[0] : sorter;
+    const sort = order ? `${order === 'descend' ? '-' : ''}${field}` : 'id';
+
+    setQuery((prev) => ({
+      ...prev,
+      page: sort === prev.sort ? pagination.current ?? 1 : 1,
+      limit: pagination.pageSize ?? prev.limit,
+      sort,
+    }));
+  };
 
   const columns = [
-    { title: 'ID', dataIndex: 'id' },
-    { title: 'Email', dataIndex: 'email' },
+    { title: 'ID', dataIndex: 'id', sorter: true },
+    { title: 'Email', dataIndex: 'email', sorter: true },
+    {
+      title: 'Created',
+      dataIndex: 'created_at',
+      sorter: true,
+      render: (value: string) => new Date(value).toLocaleString(),
+    },
+    { title: 'Created By', dataIndex: 'created_by', render: (value: string | null) => value ?? '-' },
+    { title: 'Updated By', dataIndex: 'updated_by', render: (value: string | null) => value ?? '-' },
     {
       title: 'Actions',
       render: (user: User) => (
@@ -103,15 +171,13 @@ export default function UserPage() {
         >
           Add User
         </Button>
-        <Button onClick={fetchUsers}>Refresh</Button>
-        <Button
-          onClick={() => {
-            localStorage.clear();
-            router.push('/login');
-          }}
-        >
-          Logout
-        </Button>
+        <Input.Search
+          allowClear
+          placeholder="Search by email"
+          onSearch={(email) => setQuery((prev) => ({ ...prev, email, page: 1 }))}
+        />
+        <Button onClick={() => fetchUsers()}>Refresh</Button>
+        <Button onClick={logout}>Logout</Button>
       </Space>
 
       <Table
@@ -119,6 +185,14 @@ export default function UserPage() {
         columns={columns}
         dataSource={users}
         loading={loading}
+        onChange={onTableChange}
+        pagination={{
+          current: query.page,
+          pageSize: query.limit,
+          total,
+          showSizeChanger: true,
+          pageSizeOptions: [10, 20, 50, 100],
+        }}
         bordered
       />
 
@@ -0,0 +1,3 @@
+export const authHeaders = () => ({
+  headers: { Authorization: `Bearer ${localStorage.getItem('token') ?? ''}` },
+});